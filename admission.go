@@ -0,0 +1,298 @@
+package taskgroup
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ShedPolicy 表示待执行的任务数达到上限时的丢弃策略
+type ShedPolicy uint8
+
+const (
+	// ShedReject 拒绝新添加的任务
+	ShedReject ShedPolicy = iota
+	// ShedDropLowestPriority 丢弃优先级最低的非必要成功任务(含新添加的任务)，优先级相同时，保留先添加的任务
+	ShedDropLowestPriority
+	// ShedBlock 不丢弃任何任务，[TaskGroup.AddTask]将阻塞，直至运行中的任务组取走(开始执行)任务，见[TaskGroup.CloseTasks]
+	ShedBlock
+)
+
+// ErrQueueFull 任务因待执行的任务数达到上限而被丢弃
+var ErrQueueFull = errors.New("taskgroup: task queue is full")
+
+// WithMaxQueuedTasks 指定任务组中待执行任务数的上限`maxQueuedTasks`，以及达到上限时的丢弃策略`policy`
+//
+// 被丢弃的任务将出现在任务组的执行结果中，其[TaskResult.Shed]为`true`，当必要成功的任务被丢弃时，任务组将执行失败；
+// 通过[TaskGroup.TryAddTask]添加时，未被接纳的任务将被拒绝，而非丢弃
+//
+// [ShedBlock]策略下，任务组在运行期间仍可(在其他协程中)添加任务，待执行的任务数达到上限时，添加任务将阻塞，
+// 添加完所有任务后，需调用[TaskGroup.CloseTasks]，任务组执行完所有已添加的任务后，方可结束运行；
+// 运行期间添加的任务不会从检查点恢复(见[WithCheckpointer])，且仅可依赖已添加的任务，否则视为依赖执行失败
+func WithMaxQueuedTasks(maxQueuedTasks uint32, policy ShedPolicy) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.maxQueuedTasks = maxQueuedTasks
+		tg.shedPolicy = policy
+		tg.feed = nil
+		if maxQueuedTasks > 0 && policy == ShedBlock {
+			tg.feed = newTaskFeed()
+		}
+	}
+}
+
+// WithPriority 指定任务的优先级`priority`，值越大优先级越高，默认为0，用于[ShedDropLowestPriority]丢弃策略
+func WithPriority(priority int) TaskOption {
+	return func(t *Task) {
+		if t == nil {
+			return
+		}
+		t.priority = priority
+	}
+}
+
+// TryAddTask 同[TaskGroup.AddTask]，但未被接纳的任务将被拒绝，不属于任务组(不会出现在执行结果中，且可再次添加)，
+// 当有任务被拒绝或因待执行的任务数达到上限而被丢弃时，返回[ErrQueueFull]；[ShedBlock]策略下，待执行的任务数达到上限时不会阻塞，而是拒绝任务
+func (tg *TaskGroup) TryAddTask(tasks ...*Task) error {
+	if tg == nil {
+		return nil
+	}
+	if tg.feed != nil {
+		if !tg.feedTasks(tasks, true) {
+			return ErrQueueFull
+		}
+		return nil
+	}
+
+	var (
+		shedNums = len(tg.shedTasks)
+		rejected bool
+	)
+	tg.addTask(tasks, func(*Task) { rejected = true })
+	if rejected || len(tg.shedTasks) > shedNums {
+		return ErrQueueFull
+	}
+	return nil
+}
+
+// shed 丢弃未被接纳的任务`task`，其仍属于任务组，并出现在执行结果中
+func (tg *TaskGroup) shed(task *Task) {
+	tg.fNOs[task.fNO] = struct{}{}
+	tg.shedTasks = append(tg.shedTasks, task)
+}
+
+// queueFull 待执行的任务数是否已达上限
+func (tg *TaskGroup) queueFull() bool {
+	return tg.maxQueuedTasks > 0 && uint32(len(tg.tasks)) >= tg.maxQueuedTasks
+}
+
+// admit 待执行的任务数已达上限时，按照丢弃策略决定是否接纳任务`task`
+func (tg *TaskGroup) admit(task *Task) bool {
+	if tg.shedPolicy != ShedDropLowestPriority {
+		return false
+	}
+
+	// 优先级相同时，丢弃后添加的任务
+	victim := -1
+	for i, queued := range tg.tasks {
+		if !queued.mustSuccess && (victim < 0 || queued.priority <= tg.tasks[victim].priority) {
+			victim = i
+		}
+	}
+	// 新添加的任务为必要成功任务或优先级更高时，丢弃已有的优先级最低的任务
+	if victim < 0 || !task.mustSuccess && tg.tasks[victim].priority >= task.priority {
+		return false
+	}
+	tg.shedTasks = append(tg.shedTasks, tg.tasks[victim])
+	tg.tasks = append(tg.tasks[:victim], tg.tasks[victim+1:]...)
+	return true
+}
+
+// shedErr 获取被丢弃的任务`shedTasks`中首个必要成功任务的错误信息
+func shedErr(shedTasks []*Task) error {
+	for _, task := range shedTasks {
+		if task.mustSuccess {
+			return &TaskError{FNO: task.fNO, Name: task.name, MustSuccess: true, Err: ErrQueueFull}
+		}
+	}
+	return nil
+}
+
+// CloseTasks 表示不再向任务组中添加任务，仅[ShedBlock]策略下需调用，运行中的任务组执行完所有已添加的任务后，方可结束运行
+//
+// NOTEs: 调用后再添加任务，将会`panic`
+func (tg *TaskGroup) CloseTasks() {
+	if tg == nil || tg.feed == nil {
+		return
+	}
+
+	f := tg.feed
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	f.cond.Broadcast()
+	f.run.close()
+}
+
+// taskFeed [ShedBlock]策略下，任务组运行期间持续添加的任务
+type taskFeed struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	run    *feedRun // 运行中的任务组，未运行时为`nil`
+	closed bool     // 是否已不再添加任务，见[TaskGroup.CloseTasks]
+	locked bool     // 任务组是否正在准备运行(持有锁，暂停添加任务)
+}
+
+func newTaskFeed() *taskFeed {
+	f := new(taskFeed)
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// feedTasks 向任务组中添加若干任务`tasks`，待执行的任务数达到上限时，阻塞直至运行中的任务组取走任务，
+// `try`为`true`时，不阻塞而拒绝任务，并返回`false`
+func (tg *TaskGroup) feedTasks(tasks []*Task, try bool) bool {
+	f := tg.feed
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		panic("AddTask: task group is closed")
+	}
+	f.mu.Unlock()
+
+	accepted := true
+	for _, task := range tasks {
+		if task == nil || !task.runnable() {
+			continue
+		}
+		accepted = tg.feedTask(task, try) && accepted
+	}
+	return accepted
+}
+
+func (tg *TaskGroup) feedTask(task *Task, try bool) bool {
+	f := tg.feed
+	f.mu.Lock()
+	if _, exist := tg.fNOs[task.fNO]; exist { // 已经有相同的任务了
+		f.mu.Unlock()
+		panic(fmt.Sprintf("AddTask: Already have the same Task %s", task.ident()))
+	}
+	// 任务组还未运行时，等待其开始运行
+	for f.run == nil && !f.closed && tg.queueFull() {
+		if try {
+			f.mu.Unlock()
+			return false
+		}
+		f.cond.Wait()
+	}
+	run := f.run
+	if run != nil && try && run.tasks.full() {
+		f.mu.Unlock()
+		return false
+	}
+	tg.fNOs[task.fNO] = struct{}{}
+	tg.tasks = append(tg.tasks, task)
+	if run == nil {
+		f.mu.Unlock()
+		return true
+	}
+	// 在不再添加任务前登记，以免运行中的任务组在任务加入前结束
+	run.tasks.beginPut()
+	f.mu.Unlock()
+	run.put(task)
+	return true
+}
+
+// open 任务组开始准备运行，运行期间可持续添加任务时，返回`true`，并暂停添加任务，直至[taskFeed.attach]或[taskFeed.release]
+func (f *taskFeed) open() bool {
+	if f == nil {
+		return false
+	}
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return false
+	}
+	f.locked = true
+	return true
+}
+
+// release 任务组准备运行结束(如，提前返回)，恢复添加任务
+func (f *taskFeed) release() {
+	if f == nil || !f.locked {
+		return
+	}
+	f.locked = false
+	f.mu.Unlock()
+}
+
+// attach 任务组开始运行，此后添加的任务将加入本次运行`run`
+func (f *taskFeed) attach(run *feedRun) {
+	f.run = run
+	f.cond.Broadcast()
+	f.release()
+}
+
+// detach 任务组运行结束，返回本次运行的所有任务
+func (f *taskFeed) detach() []*Task {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	run := f.run
+	f.run = nil
+	return run.end()
+}
+
+// feedRun 任务组单次运行期间持续添加的任务
+type feedRun struct {
+	r     *runner
+	tg    *TaskGroup
+	tasks *pendingDispatcher
+
+	mu    sync.Mutex
+	all   []*Task // 本次运行的所有任务
+	ended bool
+}
+
+func (r *runner) newFeedRun(tg *TaskGroup, tasks *pendingDispatcher, pendingTasks []*Task) *feedRun {
+	return &feedRun{r: r, tg: tg, tasks: tasks, all: append([]*Task(nil), pendingTasks...)}
+}
+
+// put 将任务`task`加入本次运行，与标签选择器不匹配的任务不会执行
+func (fr *feedRun) put(task *Task) {
+	if len(fr.tg.selectTasks([]*Task{task})) == 0 {
+		fr.tasks.endPut()
+		return
+	}
+
+	fr.mu.Lock()
+	if fr.ended {
+		fr.mu.Unlock()
+		fr.tasks.endPut()
+		return
+	}
+	fr.all = append(fr.all, task)
+	fr.r.deps.add(task)
+	fr.r.cleaner.add(task)
+	fr.r.stats.addTasks(1)
+	fr.r.events.queued([]*Task{task})
+	fr.mu.Unlock()
+	fr.tasks.put(task)
+}
+
+// close 不再添加任务
+func (fr *feedRun) close() {
+	if fr == nil {
+		return
+	}
+	fr.tasks.closeFeed()
+}
+
+// end 结束本次运行，返回本次运行的所有任务
+func (fr *feedRun) end() []*Task {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	fr.ended = true
+	return fr.all
+}
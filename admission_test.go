@@ -0,0 +1,205 @@
+package taskgroup_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_admission(t *testing.T) {
+	newTasks := func() []*taskgroup.Task {
+		return []*taskgroup.Task{
+			taskgroup.NewTask(1, task2ReturnSuccessWrapper(1, false), false, taskgroup.WithPriority(1)),
+			taskgroup.NewTask(2, task2ReturnSuccessWrapper(2, false), false),
+			taskgroup.NewTask(3, task4ReturnSuccessWrapper(3, false), true),
+			taskgroup.NewTask(4, task4ReturnSuccessWrapper(4, false), false, taskgroup.WithPriority(2)),
+		}
+	}
+
+	testCases := []struct {
+		policy    taskgroup.ShedPolicy
+		try       bool
+		shedFNOs  map[uint32]bool
+		isTryFull bool
+		taskNums  int
+	}{
+		{taskgroup.ShedReject, false, map[uint32]bool{3: true, 4: true}, false, 4},
+		{taskgroup.ShedReject, true, map[uint32]bool{}, true, 2},
+		{taskgroup.ShedDropLowestPriority, true, map[uint32]bool{1: true, 2: true}, true, 4},
+	}
+	for _, testCase := range testCases {
+		tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(1), taskgroup.WithMaxQueuedTasks(2, testCase.policy))
+		if testCase.try {
+			if err := tg.TryAddTask(newTasks()...); errors.Is(err, taskgroup.ErrQueueFull) != testCase.isTryFull {
+				t.Errorf("policy=%d, err=%+v", testCase.policy, err)
+			}
+		} else {
+			tg.AddTask(newTasks()...)
+		}
+		results, err := tg.Run()
+
+		// 必要成功的任务`3`被丢弃时，任务组执行失败
+		var taskErr *taskgroup.TaskError
+		if isShed := testCase.shedFNOs[3]; isShed != (errors.As(err, &taskErr) && taskErr.FNO == 3) {
+			t.Errorf("policy=%d, err=%+v", testCase.policy, err)
+		}
+		// 任务组执行失败时，将不再执行其他任务，执行结果中仅有被丢弃的任务；被拒绝的任务不会出现在执行结果中
+		if expected := len(testCase.shedFNOs); err == nil && len(results) != testCase.taskNums || err != nil && len(results) != expected {
+			t.Fatalf("policy=%d, results=%+v", testCase.policy, results)
+		}
+		for fNO, result := range results {
+			if isShed := testCase.shedFNOs[fNO]; result.Shed() != isShed || isShed != errors.Is(result.Error(), taskgroup.ErrQueueFull) {
+				t.Errorf("policy=%d, fno=%d, shed=%v, err=%+v", testCase.policy, fNO, result.Shed(), result.Error())
+			}
+		}
+	}
+}
+
+func TestTaskGroupTryAddTask_rejected(t *testing.T) {
+	tg := taskgroup.NewTaskGroup(taskgroup.WithMaxQueuedTasks(1, taskgroup.ShedReject))
+	if err := tg.TryAddTask(taskgroup.NewTask(1, task2ReturnSuccessWrapper(1, false), false)); err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+	rejected := taskgroup.NewTask(2, task2ReturnSuccessWrapper(2, false), true)
+	if err := tg.TryAddTask(rejected); !errors.Is(err, taskgroup.ErrQueueFull) {
+		t.Fatalf("err=%+v", err)
+	}
+	// 被拒绝的任务不属于任务组，可再次添加
+	if err := tg.TryAddTask(rejected); !errors.Is(err, taskgroup.ErrQueueFull) {
+		t.Fatalf("err=%+v", err)
+	}
+	results, err := tg.Run()
+	if err != nil || len(results) != 1 || results[1] == nil {
+		t.Errorf("results=%+v, err=%+v", results, err)
+	}
+}
+
+func TestTaskGroupRun_admissionPriorityTie(t *testing.T) {
+	tg := taskgroup.NewTaskGroup(taskgroup.WithMaxQueuedTasks(2, taskgroup.ShedDropLowestPriority)).AddTask(
+		taskgroup.NewTask(1, task2ReturnSuccessWrapper(1, false), false),
+		taskgroup.NewTask(2, task2ReturnSuccessWrapper(2, false), false),
+		taskgroup.NewTask(3, task4ReturnSuccessWrapper(3, false), true),
+	)
+	results, err := tg.Run()
+	if err != nil || len(results) != 3 {
+		t.Fatalf("results=%+v, err=%+v", results, err)
+	}
+	// 优先级相同时，保留先添加的任务
+	if results[1].Shed() || !results[2].Shed() || results[3].Shed() {
+		t.Errorf("shed=[%v %v %v]", results[1].Shed(), results[2].Shed(), results[3].Shed())
+	}
+}
+
+func TestTaskGroupRun_admissionBlock(t *testing.T) {
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(1), taskgroup.WithMaxQueuedTasks(2, taskgroup.ShedBlock))
+	tg.AddTask(taskgroup.NewTask(1, func() (interface{}, error) {
+		close(started)
+		<-release
+		return 1, nil
+	}, true))
+	added := make(chan struct{})
+	go func() {
+		defer close(added)
+		tg.AddTask(taskgroup.NewTask(2, task2ReturnSuccessWrapper(2, false), false), taskgroup.NewTask(3, task2ReturnSuccessWrapper(3, false), false))
+	}()
+	// 任务组运行前，待执行的任务数达到上限时阻塞
+	select {
+	case <-added:
+		t.Fatal("AddTask did not block before run")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	h := tg.Start()
+	<-started
+	<-added
+	// 任务组运行期间，待执行的任务数达到上限时阻塞，直至有任务被取走；TryAddTask则拒绝任务
+	if err := tg.TryAddTask(taskgroup.NewTask(5, task2ReturnSuccessWrapper(5, false), false)); !errors.Is(err, taskgroup.ErrQueueFull) {
+		t.Errorf("err=%+v", err)
+	}
+	added = make(chan struct{})
+	go func() {
+		defer close(added)
+		tg.AddTask(taskgroup.NewTask(4, task2ReturnSuccessWrapper(4, false), false))
+	}()
+	select {
+	case <-added:
+		t.Fatal("AddTask did not block while running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	<-added
+	tg.CloseTasks()
+
+	results, err := h.Wait()
+	if err != nil || len(results) != 4 {
+		t.Fatalf("results=%+v, err=%+v", results, err)
+	}
+	for fNO, result := range results {
+		if result.Shed() || result.Error() != nil {
+			t.Errorf("fno=%d, shed=%v, err=%+v", fNO, result.Shed(), result.Error())
+		}
+	}
+
+	// 不再添加任务后，添加任务将会`panic`
+	defer func() {
+		if recover() == nil {
+			t.Error("want panic after CloseTasks")
+		}
+	}()
+	tg.AddTask(taskgroup.NewTask(6, task2ReturnSuccessWrapper(6, false), false))
+}
+
+func TestTaskGroupRun_admissionBlockFeed(t *testing.T) {
+	const taskNums = 10
+	var (
+		mu    sync.Mutex
+		order []uint32
+	)
+	tg := taskgroup.NewTaskGroup(taskgroup.WithMaxQueuedTasks(1, taskgroup.ShedBlock), taskgroup.WithTagLimit("db", 1))
+	h := tg.Start()
+	futures := make([]*taskgroup.Future, 0, taskNums)
+	for fNO := uint32(1); fNO <= taskNums; fNO++ {
+		fNO := fNO
+		opts := []taskgroup.TaskOption{taskgroup.WithClass(taskgroup.If(fNO%2 == 0, taskgroup.ClassCPU, taskgroup.ClassIO).(taskgroup.TaskClass)), taskgroup.WithTags("db")}
+		if fNO > 1 {
+			opts = append(opts, taskgroup.WithDependencies(fNO-1))
+		}
+		futures = append(futures, tg.Submit(taskgroup.NewTask(fNO, func() (interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, fNO)
+			return fNO, nil
+		}, false, opts...)))
+	}
+	// 运行期间添加的任务仅可依赖已添加的任务
+	tg.AddTask(taskgroup.NewTask(taskNums+1, task2ReturnSuccessWrapper(taskNums+1, false), false, taskgroup.WithDependencies(taskNums+2)))
+	// 运行期间添加的任务执行结束后，即可通过句柄获取执行结果
+	if _, err := taskgroup.AwaitAll(context.Background(), futures...); err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+	tg.CloseTasks()
+
+	results, err := h.Wait()
+	if err != nil || len(results) != taskNums+1 {
+		t.Fatalf("results=%d, err=%+v", len(results), err)
+	}
+	for i, fNO := range order {
+		if fNO != uint32(i+1) {
+			t.Fatalf("order=%v", order)
+		}
+	}
+	if err := results[taskNums+1].Error(); !errors.Is(err, taskgroup.ErrDependencyFailed) || !errors.Is(err, taskgroup.ErrTaskNotFound) {
+		t.Errorf("err=%+v", err)
+	}
+	if stats := tg.Stats(); stats.Tasks != taskNums+1 {
+		t.Errorf("stats=%+v", stats)
+	}
+}
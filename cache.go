@@ -0,0 +1,355 @@
+package taskgroup
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ResultCache 表示任务结果的缓存，以任务的缓存键(见[WithCacheKey])进行存取
+//
+// 缓存的读写失败不会导致任务执行失败，读取失败时视为未命中，写入失败时将被忽略；
+// 任务组访问[LRUCache]与[DirCache]时，缓存项的过期时间以任务组的时钟(见[WithGroupClock])计算
+type ResultCache interface {
+	// Get 获取缓存键`key`对应的任务结果，未命中(含已过期)时，返回`false`
+	Get(key string) (interface{}, bool, error)
+	// Set 缓存任务结果
+	Set(key string, result interface{}) error
+}
+
+// WithResultCache 指定任务组的任务结果缓存`cache`，指定了缓存键的任务在命中缓存时将不再执行，
+// 其执行结果直接取自缓存([TaskResult.Cached]为`true`)，仅执行成功的任务结果会被缓存
+func WithResultCache(cache ResultCache) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.resultCache = cache
+	}
+}
+
+// WithCacheKey 指定任务结果的缓存键`key`，应由任务的所有输入推导得出(如，[CacheKey])，输入相同时，缓存键也应相同
+func WithCacheKey(key string) TaskOption {
+	return func(t *Task) {
+		if t == nil {
+			return
+		}
+		t.cacheKey = key
+	}
+}
+
+// CacheKey 以任务的输入`inputs`(需可被`json`编码)的摘要作为缓存键
+func CacheKey(inputs ...interface{}) (string, error) {
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return "", fmt.Errorf("CacheKey: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Cached 获取任务结果是否来自缓存(任务未执行)
+func (tr *TaskResult) Cached() bool {
+	if tr == nil {
+		return false
+	}
+	return tr.cached
+}
+
+// clockedCache 以指定的当前时间`now`计算缓存项是否过期的缓存，以便任务组使用其时钟
+type clockedCache interface {
+	// getAt 获取缓存，缓存的是编码后的任务结果时，返回[*encodedResult]
+	getAt(key string, now time.Time) (interface{}, bool, error)
+	setAt(key string, result interface{}, now time.Time) error
+}
+
+// taskCache 任务组运行时对结果缓存的访问
+type taskCache struct {
+	cache ResultCache
+	clock Clock
+}
+
+func (tg *TaskGroup) newTaskCache() *taskCache {
+	if tg.resultCache == nil {
+		return nil
+	}
+	return &taskCache{tg.resultCache, tg.runClock()}
+}
+
+// lookup 查找任务`task`的缓存结果，未命中时，返回`nil`
+func (tc *taskCache) lookup(task *Task) *TaskResult {
+	if tc == nil || task.cacheKey == "" {
+		return nil
+	}
+
+	var (
+		result interface{}
+		hit    bool
+		err    error
+	)
+	if cache, ok := tc.cache.(clockedCache); ok {
+		result, hit, err = cache.getAt(task.cacheKey, tc.clock.Now())
+	} else {
+		result, hit, err = tc.cache.Get(task.cacheKey)
+	}
+	if err != nil || !hit {
+		return nil
+	}
+	taskResult := newTaskResult(task, nil, nil)
+	taskResult.cached = true
+	// 编码后的任务结果按任务的结果类型解码，解码失败时视为未命中
+	if encoded, ok := result.(*encodedResult); ok {
+		if err = taskResult.decodeResult(encoded, task.resultType); err != nil {
+			return nil
+		}
+		return taskResult
+	}
+	taskResult.result = result
+	return taskResult
+}
+
+// store 缓存任务`task`执行成功的结果
+func (tc *taskCache) store(task *Task, result *TaskResult) {
+	if tc == nil || task.cacheKey == "" || result.err != nil {
+		return
+	}
+	if cache, ok := tc.cache.(clockedCache); ok {
+		_ = cache.setAt(task.cacheKey, result.result, tc.clock.Now())
+		return
+	}
+	_ = tc.cache.Set(task.cacheKey, result.result)
+}
+
+// LRUCache 基于内存的`LRU`任务结果缓存
+type LRUCache struct {
+	maxEntries int
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	ll      *list.List
+	entries map[string]*list.Element
+}
+
+// lruEntry 缓存项
+type lruEntry struct {
+	key       string
+	result    interface{}
+	expiresAt time.Time // 为零值时，表示永不过期
+}
+
+// NewLRUCache 创建一个至多缓存`maxEntries`项的`LRU`缓存，缓存项在`ttl`后过期，`maxEntries`或`ttl`为0时，表示不限制
+func NewLRUCache(maxEntries int, ttl time.Duration) *LRUCache {
+	return &LRUCache{maxEntries: maxEntries, ttl: ttl, ll: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get 获取缓存
+func (c *LRUCache) Get(key string) (interface{}, bool, error) {
+	return c.getAt(key, time.Now())
+}
+
+func (c *LRUCache) getAt(key string, now time.Time) (interface{}, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, has := c.entries[key]
+	if !has {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && now.After(entry.expiresAt) {
+		c.ll.Remove(elem)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.ll.MoveToFront(elem)
+	return entry.result, true, nil
+}
+
+// Set 写入缓存，缓存项数超过上限时，淘汰最近最少使用的缓存项
+func (c *LRUCache) Set(key string, result interface{}) error {
+	return c.setAt(key, result, time.Now())
+}
+
+func (c *LRUCache) setAt(key string, result interface{}, now time.Time) error {
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = now.Add(c.ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, has := c.entries[key]; has {
+		entry := elem.Value.(*lruEntry)
+		entry.result, entry.expiresAt = result, expiresAt
+		c.ll.MoveToFront(elem)
+		return nil
+	}
+	c.entries[key] = c.ll.PushFront(&lruEntry{key, result, expiresAt})
+	for c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// Len 获取缓存项数(含已过期但还未淘汰的缓存项)
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// DirCache 基于磁盘目录的任务结果缓存，每个缓存项为目录下的一个文件
+type DirCache struct {
+	dir      string
+	codec    Codec
+	ttl      time.Duration
+	maxBytes int64
+
+	mu sync.Mutex
+}
+
+// dirEntry 缓存文件的内容，任务结果单独编码，以便按任务的结果类型解码(见[WithResultType])
+type dirEntry struct {
+	Key       string          `json:"key"`
+	Result    json.RawMessage `json:"result"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// NewDirCache 创建(或打开已有的)目录`dir`作为缓存，`codec`为`nil`时，默认使用[JSONCodec]，
+// 缓存项在`ttl`后过期，所有缓存文件的总大小超过`maxBytes`时，淘汰最早写入的缓存项，`ttl`或`maxBytes`为0时，表示不限制
+func NewDirCache(dir string, codec Codec, ttl time.Duration, maxBytes int64) (*DirCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	return &DirCache{dir: dir, codec: codec, ttl: ttl, maxBytes: maxBytes}, nil
+}
+
+// path 获取缓存键`key`对应的缓存文件路径
+func (c *DirCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".cache")
+}
+
+// Get 获取缓存，任务结果将被解码为编码方式的通用类型
+func (c *DirCache) Get(key string) (interface{}, bool, error) {
+	result, hit, err := c.getAt(key, time.Now())
+	if err != nil || !hit {
+		return nil, hit, err
+	}
+	v, err := result.(*encodedResult).decode(nil)
+	if err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+func (c *DirCache) getAt(key string, now time.Time) (interface{}, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, err := os.ReadFile(c.path(key))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var entry dirEntry
+	if err = c.codec.Unmarshal(data, &entry); err != nil {
+		return nil, false, err
+	}
+	if entry.Key != key {
+		return nil, false, nil
+	}
+	if !entry.ExpiresAt.IsZero() && now.After(entry.ExpiresAt) {
+		_ = os.Remove(c.path(key))
+		return nil, false, nil
+	}
+	return &encodedResult{data: entry.Result, codec: c.codec}, true, nil
+}
+
+// Set 写入缓存(先写入临时文件再重命名，避免读取到不完整的缓存项)
+func (c *DirCache) Set(key string, result interface{}) error {
+	return c.setAt(key, result, time.Now())
+}
+
+func (c *DirCache) setAt(key string, result interface{}, now time.Time) error {
+	encoded, err := c.codec.Marshal(result)
+	if err != nil {
+		return err
+	}
+	entry := dirEntry{Key: key, Result: encoded}
+	if c.ttl > 0 {
+		entry.ExpiresAt = now.Add(c.ttl)
+	}
+	data, err := c.codec.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tmp, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return err
+	}
+	return c.evict()
+}
+
+// evict 所有缓存文件的总大小超过上限时，淘汰最早写入的缓存项，调用方需持有锁
+func (c *DirCache) evict() error {
+	if c.maxBytes <= 0 {
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.cache"))
+	if err != nil {
+		return err
+	}
+	var (
+		infos = make([]os.FileInfo, 0, len(files))
+		total int64
+	)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		infos = append(infos, info)
+		total += info.Size()
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ModTime().Before(infos[j].ModTime()) })
+	for _, info := range infos {
+		if total <= c.maxBytes {
+			break
+		}
+		if err = os.Remove(filepath.Join(c.dir, info.Name())); err != nil {
+			return err
+		}
+		total -= info.Size()
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package taskgroup_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+	"github.com/mlee-msl/taskgroup/taskgrouptest"
+)
+
+// brokenCache 读写均失败的缓存
+type brokenCache struct{}
+
+func (brokenCache) Get(string) (interface{}, bool, error) { return nil, false, errors.New("get err") }
+func (brokenCache) Set(string, interface{}) error         { return errors.New("set err") }
+
+func TestTaskGroupRun_resultCache(t *testing.T) {
+	dirCache, err := taskgroup.NewDirCache(t.TempDir(), nil, time.Hour, 1<<20)
+	if err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+	testCases := []struct {
+		name      string
+		cache     taskgroup.ResultCache
+		cacheable bool
+	}{
+		{"lru", taskgroup.NewLRUCache(8, time.Hour), true},
+		{"dir", dirCache, true},
+		{"broken", brokenCache{}, false},
+	}
+	for _, testCase := range testCases {
+		var execs atomic.Int32
+		newTasks := func() []*taskgroup.Task {
+			key, _ := taskgroup.CacheKey("thumbnail", 128)
+			return []*taskgroup.Task{
+				taskgroup.NewTask(1, func() (interface{}, error) {
+					execs.Add(1)
+					return "thumbnail-128", nil
+				}, true, taskgroup.WithCacheKey(key)),
+				taskgroup.NewTask(2, func() (interface{}, error) {
+					execs.Add(1)
+					return nil, errors.New("not cacheable")
+				}, false, taskgroup.WithCacheKey("failed")),
+			}
+		}
+
+		for run := 1; run <= 2; run++ {
+			results, err := taskgroup.NewTaskGroup(taskgroup.WithResultCache(testCase.cache)).AddTask(newTasks()...).Run()
+			if err != nil || results[1].Result() != "thumbnail-128" {
+				t.Fatalf("cache=%s, results=%+v, err=%+v", testCase.name, results, err)
+			}
+			if cached := run == 2 && testCase.cacheable; results[1].Cached() != cached || results[2].Cached() {
+				t.Errorf("cache=%s, run=%d, cached=%v", testCase.name, run, results[1].Cached())
+			}
+		}
+		if expected := taskgroup.If(testCase.cacheable, int32(3), int32(4)).(int32); execs.Load() != expected {
+			t.Errorf("cache=%s, execs=%d, expected=%d", testCase.name, execs.Load(), expected)
+		}
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	cache := taskgroup.NewLRUCache(2, 20*time.Millisecond)
+	_ = cache.Set("a", 1)
+	_ = cache.Set("b", 2)
+	_, _, _ = cache.Get("a")
+	_ = cache.Set("c", 3) // 淘汰最近最少使用的`b`
+	if _, hit, _ := cache.Get("b"); hit || cache.Len() != 2 {
+		t.Errorf("hit=%v, len=%d", hit, cache.Len())
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, hit, _ := cache.Get("a"); hit {
+		t.Errorf("expected expired")
+	}
+}
+
+// thumbnail 可被编码的任务结果
+type thumbnail struct {
+	Size int    `json:"size"`
+	URL  string `json:"url"`
+}
+
+func TestTaskGroupRun_resultCacheClock(t *testing.T) {
+	dirCache, err := taskgroup.NewDirCache(t.TempDir(), nil, time.Hour, 0)
+	if err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+	for _, cache := range []taskgroup.ResultCache{taskgroup.NewLRUCache(8, time.Hour), dirCache} {
+		var (
+			clock = taskgrouptest.NewFakeClock(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+			execs atomic.Int32
+		)
+		run := func() *taskgroup.TaskResult {
+			results, err := taskgroup.NewTaskGroup(taskgroup.WithResultCache(cache), taskgroup.WithGroupClock(clock)).AddTask(
+				taskgroup.NewTask(1, func() (interface{}, error) {
+					execs.Add(1)
+					return thumbnail{128, "https://cdn.example.com/128.png"}, nil
+				}, true, taskgroup.WithCacheKey("thumbnail"), taskgroup.WithResultType(thumbnail{})),
+			).Run()
+			if err != nil {
+				t.Fatalf("cache=%T, err=%+v", cache, err)
+			}
+			return results[1]
+		}
+
+		run()
+		// 取自磁盘缓存的任务结果同样被解码为任务的结果类型
+		clock.Advance(30 * time.Minute)
+		if result := run(); !result.Cached() || result.Result() != (thumbnail{128, "https://cdn.example.com/128.png"}) {
+			t.Errorf("cache=%T, cached=%v, result=%#v", cache, result.Cached(), result.Result())
+		}
+		// 缓存项的过期时间以任务组的时钟计算
+		clock.Advance(time.Hour)
+		if result := run(); result.Cached() || execs.Load() != 2 {
+			t.Errorf("cache=%T, cached=%v, execs=%d", cache, result.Cached(), execs.Load())
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package taskgroup
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// Checkpointer 表示任务执行结果的检查点记录器，用以中断后的任务组恢复执行
+type Checkpointer interface {
+	// Record 记录一个已执行完成的任务结果
+	Record(result *TaskResult) error
+	// Load 加载所有已记录的任务结果，同一任务存在多条记录时，以最后一条为准
+	Load() (map[uint32]*TaskResult, error)
+}
+
+// WithCheckpointer 指定任务组的检查点记录器`checkpointer`，每个执行完成的任务结果都将被记录
+//
+// 当`resume`为`true`时，任务组运行时将跳过已记录为执行成功的任务，并直接使用其记录的执行结果
+func WithCheckpointer(checkpointer Checkpointer, resume bool) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.checkpointer = checkpointer
+		tg.resume = resume
+	}
+}
+
+// Codec 表示任务执行结果的编解码方式
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec 基于`encoding/json`的编解码实现
+//
+// NOTEs: 任务未指定结果类型(见[WithResultType])时，解码后的任务结果将为`json`的通用类型(如，`map[string]interface{}`、`float64`等)，
+// 但仍可通过[ResultAs]解码为指定的类型
+type JSONCodec struct{}
+
+// Marshal 编码
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal 解码
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// WithResultType 指定任务结果的类型为`prototype`的类型(如，`WithResultType(Order{})`)，
+// 任务结果从检查点恢复、取自磁盘缓存或回放(见[WithReplay])时，将被解码为该类型，而非编码方式的通用类型，从而与任务执行所得的结果类型一致
+func WithResultType(prototype interface{}) TaskOption {
+	return func(t *Task) {
+		if t == nil {
+			return
+		}
+		t.resultType = reflect.TypeOf(prototype)
+	}
+}
+
+// encodedResult 编码后的任务结果，以便按需解码为指定的类型
+type encodedResult struct {
+	data  []byte
+	codec Codec
+}
+
+// decode 将任务结果解码为`typ`类型，`typ`为`nil`时，解码为编码方式的通用类型
+func (er *encodedResult) decode(typ reflect.Type) (interface{}, error) {
+	if typ == nil {
+		var v interface{}
+		err := er.codec.Unmarshal(er.data, &v)
+		return v, err
+	}
+	v := reflect.New(typ)
+	if err := er.codec.Unmarshal(er.data, v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
+
+// decodeResult 以编码后的任务结果`encoded`设置任务结果，并解码为`typ`类型(见[encodedResult.decode])
+func (tr *TaskResult) decodeResult(encoded *encodedResult, typ reflect.Type) error {
+	result, err := encoded.decode(typ)
+	if err != nil {
+		return err
+	}
+	tr.result, tr.encoded = result, encoded
+	return nil
+}
+
+// journalEntry 日志中的一条任务结果记录，任务结果单独编码，以便恢复时按任务的结果类型解码
+type journalEntry struct {
+	FNO    uint32          `json:"fno"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Err    string          `json:"err,omitempty"`
+	Failed bool            `json:"failed,omitempty"`
+}
+
+// journalHeaderSize 每条记录的头部长度，依次为，负载长度(4字节)与负载的`crc32`校验和(4字节)
+const journalHeaderSize = 8
+
+// FileCheckpointer 基于文件的检查点记录器，以仅追加的日志形式记录任务结果
+//
+// 日志尾部的不完整或损坏的记录(如，写入过程中进程崩溃)将在加载时被忽略并截断
+type FileCheckpointer struct {
+	mu    sync.Mutex
+	file  *os.File
+	codec Codec
+}
+
+// NewFileCheckpointer 创建(或打开已有的)日志文件`path`作为检查点记录器，`codec`为`nil`时，默认使用[JSONCodec]
+func NewFileCheckpointer(path string, codec Codec) (*FileCheckpointer, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	return &FileCheckpointer{file: file, codec: codec}, nil
+}
+
+// Record 将任务结果追加到日志中，并确保落盘
+func (fc *FileCheckpointer) Record(result *TaskResult) error {
+	if fc == nil || result == nil {
+		return nil
+	}
+
+	data, err := fc.codec.Marshal(result.result)
+	if err != nil {
+		return err
+	}
+	entry := journalEntry{FNO: result.fNO, Result: data}
+	if result.err != nil {
+		entry.Err, entry.Failed = result.err.Error(), true
+	}
+	payload, err := fc.codec.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	record := make([]byte, journalHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[journalHeaderSize:], payload)
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if _, err = fc.file.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	if _, err = fc.file.Write(record); err != nil {
+		return err
+	}
+	return fc.file.Sync()
+}
+
+// Load 加载日志中所有已记录的任务结果，任务结果为编码方式的通用类型，恢复运行时，将按任务的结果类型(见[WithResultType])重新解码
+//
+// 失败任务的错误信息仅保留了其文本描述
+func (fc *FileCheckpointer) Load() (map[uint32]*TaskResult, error) {
+	if fc == nil {
+		return nil, nil
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if _, err := fc.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(fc.file)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		results = make(map[uint32]*TaskResult)
+		offset  int
+	)
+	for offset+journalHeaderSize <= len(data) {
+		size := int(binary.LittleEndian.Uint32(data[offset : offset+4]))
+		checksum := binary.LittleEndian.Uint32(data[offset+4 : offset+8])
+		end := offset + journalHeaderSize + size
+		if end > len(data) || crc32.ChecksumIEEE(data[offset+journalHeaderSize:end]) != checksum {
+			break // 尾部记录不完整或已损坏
+		}
+		var entry journalEntry
+		if err = fc.codec.Unmarshal(data[offset+journalHeaderSize:end], &entry); err != nil {
+			return nil, fmt.Errorf("Load: decode entry at offset %d: %w", offset, err)
+		}
+		result := &TaskResult{fNO: entry.FNO}
+		if len(entry.Result) > 0 {
+			if err = result.decodeResult(&encodedResult{data: entry.Result, codec: fc.codec}, nil); err != nil {
+				return nil, fmt.Errorf("Load: decode result of task %d: %w", entry.FNO, err)
+			}
+		}
+		if entry.Failed {
+			result.err = errors.New(entry.Err)
+		}
+		results[entry.FNO] = result
+		offset = end
+	}
+
+	// 截断尾部损坏的记录，保证后续追加的记录可被正常加载
+	if offset < len(data) {
+		if err = fc.file.Truncate(int64(offset)); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// Close 关闭日志文件
+func (fc *FileCheckpointer) Close() error {
+	if fc == nil {
+		return nil
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.file.Close()
+}
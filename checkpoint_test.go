@@ -0,0 +1,111 @@
+package taskgroup_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_checkpoint(t *testing.T) {
+	var (
+		path  = filepath.Join(t.TempDir(), "taskgroup.journal")
+		execs [5]atomic.Int32 // 各任务的执行次数
+	)
+	newTasks := func(failFNO uint32) []*taskgroup.Task {
+		tasks := make([]*taskgroup.Task, 0, 4)
+		for fNO := uint32(1); fNO <= 4; fNO++ {
+			fNO := fNO
+			tasks = append(tasks, taskgroup.NewTask(fNO, func() (interface{}, error) {
+				execs[fNO].Add(1)
+				if fNO == failFNO {
+					return nil, errors.New("fail")
+				}
+				return fNO * 10, nil
+			}, false))
+		}
+		return tasks
+	}
+
+	cp, err := taskgroup.NewFileCheckpointer(path, nil)
+	if err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+	if _, err = taskgroup.NewTaskGroup(taskgroup.WithCheckpointer(cp, true)).AddTask(newTasks(3)...).Run(); err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+	_ = cp.Close()
+
+	// 模拟进程崩溃导致的尾部记录损坏
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	_, _ = file.Write([]byte{0x10, 0, 0, 0, 1, 2})
+	_ = file.Close()
+
+	if cp, err = taskgroup.NewFileCheckpointer(path, taskgroup.JSONCodec{}); err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+	defer cp.Close()
+	results, err := taskgroup.NewTaskGroup(taskgroup.WithCheckpointer(cp, true)).AddTask(newTasks(0)...).Run()
+	if err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+	for fNO := uint32(1); fNO <= 4; fNO++ {
+		expected := int32(1)
+		if fNO == 3 { // 仅失败的任务需要再次执行
+			expected = 2
+		}
+		if got := execs[fNO].Load(); got != expected {
+			t.Errorf("fno=%d, execs=%d, expected=%d", fNO, got, expected)
+		}
+		if results[fNO].Error() != nil || results[fNO].Result() == nil {
+			t.Errorf("fno=%d, result=%+v", fNO, results[fNO])
+		}
+	}
+
+	recorded, err := cp.Load()
+	if err != nil || len(recorded) != 4 || recorded[3].Error() != nil {
+		t.Errorf("recorded=%+v, err=%+v", recorded, err)
+	}
+}
+
+func TestTaskGroupRun_checkpointResultType(t *testing.T) {
+	type point struct{ X, Y int }
+	var (
+		path  = filepath.Join(t.TempDir(), "taskgroup.journal")
+		execs atomic.Int32
+	)
+	newTasks := func(opts ...taskgroup.TaskOption) []*taskgroup.Task {
+		return []*taskgroup.Task{
+			taskgroup.NewTask(1, func() (interface{}, error) {
+				execs.Add(1)
+				return point{1, 2}, nil
+			}, true, opts...),
+		}
+	}
+
+	cp, err := taskgroup.NewFileCheckpointer(path, nil)
+	if err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+	defer cp.Close()
+	for run, opts := range [][]taskgroup.TaskOption{nil, {taskgroup.WithResultType(point{})}, nil} {
+		results, err := taskgroup.NewTaskGroup(taskgroup.WithCheckpointer(cp, true)).AddTask(newTasks(opts...)...).Run()
+		if err != nil {
+			t.Fatalf("run=%d, err: %+v", run, err)
+		}
+		// 指定了结果类型时，恢复的任务结果与执行所得的结果类型一致
+		if _, ok := results[1].Result().(point); ok != (run != 2) {
+			t.Errorf("run=%d, result=%#v", run, results[1].Result())
+		}
+		// 未指定结果类型时，仍可按需解码
+		if p, err := taskgroup.ResultAs[point](results, 1); err != nil || p != (point{1, 2}) {
+			t.Errorf("run=%d, point=%+v, err=%+v", run, p, err)
+		}
+	}
+	if execs.Load() != 1 {
+		t.Errorf("execs=%d", execs.Load())
+	}
+}
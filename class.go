@@ -0,0 +1,121 @@
+package taskgroup
+
+import (
+	"context"
+	"runtime"
+)
+
+// ioWorkersPerProc `IO`密集型任务的默认协程数为`GOMAXPROCS`的倍数
+const ioWorkersPerProc = 16
+
+// TaskClass 表示任务的类别，不同类别的任务在各自独立的`workers`上执行
+type TaskClass uint8
+
+const (
+	// ClassDefault 默认类别，由[WithWorkerNums]指定的`workers`执行
+	ClassDefault TaskClass = iota
+	// ClassCPU `CPU`密集型任务，默认协程数为`GOMAXPROCS`
+	ClassCPU
+	// ClassIO `IO`密集型任务，默认协程数为`GOMAXPROCS`的16倍
+	ClassIO
+)
+
+// WithClass 指定任务的类别`class`
+func WithClass(class TaskClass) TaskOption {
+	return func(t *Task) {
+		if t == nil {
+			return
+		}
+		t.class = class
+	}
+}
+
+// WithClassWorkers 指定类别为`class`的任务的协程数`n`，`n`为0时，使用该类别的默认协程数
+//
+// 各类别的任务在各自独立的`workers`上执行，但共享同一个执行结果集合与取消域：任一必要成功的任务失败，所有类别还未启动的任务均不再执行
+func WithClassWorkers(class TaskClass, n uint32) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		if class == ClassDefault {
+			WithWorkerNums(n)(tg)
+			return
+		}
+		if tg.classWorkers == nil {
+			tg.classWorkers = make(map[TaskClass]uint32)
+		}
+		tg.classWorkers[class] = n
+	}
+}
+
+// workerPool 执行同一类别任务的一组`workers`
+type workerPool struct {
+	tasks   dispatcher
+	workers uint32
+}
+
+// newWorkerPools 按照任务`tasks`的类别创建各自的`workers`，`workerNums`为默认类别的协程数，`deps`为任务之间的依赖，
+// `feed`为接收运行期间添加的任务的分发器(见[ShedBlock])，不为`nil`时，各类别共享该分发器，且均创建`workers`
+func (tg *TaskGroup) newWorkerPools(ctx context.Context, tasks []*Task, workerNums uint32, deps *dependencyTracker, feed *pendingDispatcher) []workerPool {
+	if feed != nil {
+		if tg.sequential || tg.replay != nil {
+			return []workerPool{{feed, workerNums}}
+		}
+		pools := make([]workerPool, 0, 3)
+		for _, class := range []TaskClass{ClassDefault, ClassCPU, ClassIO} {
+			workers := If(class == ClassDefault, workerNums, tg.classWorkerNums(class)).(uint32)
+			pools = append(pools, workerPool{classDispatcher{feed, class}, workers})
+		}
+		return pools
+	}
+
+	classTasks := make(map[TaskClass][]*Task)
+	for _, task := range tasks {
+		classTasks[task.class] = append(classTasks[task.class], task)
+	}
+	// 依次执行或所有任务均为默认类别时，无需区分
+	if tg.sequential || tg.replay != nil || len(classTasks[ClassDefault]) == len(tasks) {
+		return []workerPool{{tg.newDispatcher(ctx, tasks, deps), workerNums}}
+	}
+
+	// 限制了标签并发量或存在依赖时，各类别共享同一分发器，以使并发上限对所有类别的任务生效，且任一任务执行结束时，均可唤醒依赖其的任务
+	var shared *pendingDispatcher
+	if len(tg.tagLimits) > 0 || deps != nil {
+		shared = newPendingDispatcher(ctx, tasks, tg.tagLimits, deps)
+	}
+	pools := make([]workerPool, 0, len(classTasks))
+	for _, class := range []TaskClass{ClassDefault, ClassCPU, ClassIO} {
+		if len(classTasks[class]) == 0 {
+			continue
+		}
+		pool := workerPool{workers: If(class == ClassDefault, workerNums, tg.classWorkerNums(class)).(uint32)}
+		pool.workers = If(pool.workers > uint32(len(classTasks[class])), uint32(len(classTasks[class])), pool.workers).(uint32)
+		if shared != nil {
+			pool.tasks = classDispatcher{shared, class}
+		} else {
+			pool.tasks = tg.newDispatcher(ctx, classTasks[class], deps)
+		}
+		pools = append(pools, pool)
+	}
+	return pools
+}
+
+// classWorkerNums 获取类别为`class`的任务的协程数
+func (tg *TaskGroup) classWorkerNums(class TaskClass) uint32 {
+	if n := tg.classWorkers[class]; n > 0 {
+		return n
+	}
+	procs := uint32(runtime.GOMAXPROCS(0))
+	return If(class == ClassIO, procs*ioWorkersPerProc, procs).(uint32)
+}
+
+// classDispatcher 仅分发同一类别任务的分发器
+type classDispatcher struct {
+	*pendingDispatcher
+	class TaskClass
+}
+
+func (d classDispatcher) next(ctx context.Context) (*Task, bool) {
+	return d.take(ctx, func(task *Task) bool { return task.class == d.class })
+}
@@ -0,0 +1,95 @@
+package taskgroup_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_classes(t *testing.T) {
+	const ioTasks = 4
+	var (
+		barrier            sync.WaitGroup
+		cpuActive, cpuPeak atomic.Int32
+	)
+	barrier.Add(ioTasks)
+	tg := taskgroup.NewTaskGroup(
+		taskgroup.WithWorkerNums(1),
+		taskgroup.WithClassWorkers(taskgroup.ClassCPU, 1),
+		taskgroup.WithClassWorkers(taskgroup.ClassIO, ioTasks),
+	)
+	for fNO := uint32(1); fNO <= ioTasks; fNO++ {
+		// 所有`IO`任务需同时执行，方可结束
+		tg.AddTask(taskgroup.NewTask(fNO, func() (interface{}, error) {
+			barrier.Done()
+			done := make(chan struct{})
+			go func() {
+				barrier.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return "io", nil
+			case <-time.After(time.Second):
+				return nil, errors.New("io tasks not running concurrently")
+			}
+		}, true, taskgroup.WithClass(taskgroup.ClassIO)))
+	}
+	for fNO := uint32(ioTasks + 1); fNO <= ioTasks+3; fNO++ {
+		tg.AddTask(taskgroup.NewTask(fNO, func() (interface{}, error) {
+			n := cpuActive.Add(1)
+			defer cpuActive.Add(-1)
+			if n > cpuPeak.Load() {
+				cpuPeak.Store(n)
+			}
+			time.Sleep(5 * time.Millisecond)
+			return "cpu", nil
+		}, true, taskgroup.WithClass(taskgroup.ClassCPU)))
+	}
+	tg.AddTask(taskgroup.NewTask(100, task2ReturnSuccessWrapper(100, false), true))
+
+	results, err := tg.Run()
+	if err != nil || len(results) != ioTasks+4 {
+		t.Fatalf("results=%d, err=%+v", len(results), err)
+	}
+	if peak := cpuPeak.Load(); peak != 1 {
+		t.Errorf("cpu peak=%d", peak)
+	}
+}
+
+func TestTaskGroupRun_classesCancel(t *testing.T) {
+	var dbActive, dbPeak, ioStarted atomic.Int32
+	db := func(f func() error) taskgroup.TaskFunc {
+		return func() (interface{}, error) {
+			n := dbActive.Add(1)
+			defer dbActive.Add(-1)
+			if n > dbPeak.Load() {
+				dbPeak.Store(n)
+			}
+			return nil, f()
+		}
+	}
+	tg := taskgroup.NewTaskGroup(taskgroup.WithClassWorkers(taskgroup.ClassIO, 2), taskgroup.WithTagLimit("db", 1))
+	tg.AddTask(taskgroup.NewTask(1, db(func() error {
+		return errors.New("cpu failed")
+	}), true, taskgroup.WithClass(taskgroup.ClassCPU), taskgroup.WithTags("db")))
+	for fNO := uint32(2); fNO <= 9; fNO++ {
+		tg.AddTask(taskgroup.NewTask(fNO, db(func() error {
+			ioStarted.Add(1)
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}), false, taskgroup.WithClass(taskgroup.ClassIO), taskgroup.WithTags("db")))
+	}
+
+	// 标签并发上限对所有类别生效，必要成功的任务失败后，其他类别还未启动的任务不再执行
+	if _, err := tg.Run(); err == nil {
+		t.Fatal("expected err")
+	}
+	if peak, n := dbPeak.Load(), ioStarted.Load(); peak != 1 || n >= 8 {
+		t.Errorf("db peak=%d, io started=%d", peak, n)
+	}
+}
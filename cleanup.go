@@ -0,0 +1,163 @@
+package taskgroup
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// CleanupFunc 表示释放任务结果`result`所持有资源(如，打开的文件、`HTTP`响应体等)的方法
+type CleanupFunc func(result interface{}) error
+
+// WithCleanup 开启对被丢弃的任务结果的清理，任务组执行失败或被取消时，所有的任务结果(含已收集的)都将被清理，
+// 未被采用的推测执行、超时或被看门狗放弃的执行，其结果将在执行结束后被清理，
+// `cleanup`为`nil`时，默认对实现了`io.Closer`的结果调用`Close()`
+//
+// 已被清理的任务结果[TaskResult.Discarded]为`true`，且[TaskResult.Result]返回`nil`，以免使用已释放的资源；
+// 已通过句柄([Future])交付给调用方的任务结果不会被清理，需由调用方自行释放
+//
+// 清理失败不会改变任务组返回的错误信息，而是记录在运行统计[RunStats.CleanupErrors]中；来自缓存的任务结果不会被清理
+func WithCleanup(cleanup CleanupFunc) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.cleanup = If(cleanup != nil, cleanup, CleanupFunc(closeResult)).(CleanupFunc)
+	}
+}
+
+// WithTaskCleanup 指定任务结果的清理方法`cleanup`，优先于任务组的清理方法，未开启[WithCleanup]时，同样有效
+func WithTaskCleanup(cleanup CleanupFunc) TaskOption {
+	return func(t *Task) {
+		if t == nil {
+			return
+		}
+		t.cleanup = cleanup
+	}
+}
+
+// Discarded 获取任务结果是否已被清理，见[WithCleanup]
+func (tr *TaskResult) Discarded() bool {
+	if tr == nil {
+		return false
+	}
+	return tr.discarded.Load()
+}
+
+// closeResult 默认的清理方法
+func closeResult(result interface{}) error {
+	if closer, ok := result.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// resultCleaner 任务组单次运行期间对被丢弃的任务结果的清理
+type resultCleaner struct {
+	cleanup CleanupFunc
+
+	mu          sync.Mutex
+	taskCleanup map[uint32]CleanupFunc
+	discarded   map[*TaskResult]struct{} // 已清理的任务结果，避免重复清理
+	errs        []error
+}
+
+func (tg *TaskGroup) newResultCleaner(tasks []*Task) *resultCleaner {
+	var taskCleanup map[uint32]CleanupFunc
+	for _, task := range tasks {
+		if task.cleanup == nil {
+			continue
+		}
+		if taskCleanup == nil {
+			taskCleanup = make(map[uint32]CleanupFunc)
+		}
+		taskCleanup[task.fNO] = task.cleanup
+	}
+	// 运行期间仍可添加任务(见[ShedBlock])时，后添加的任务可能指定了清理方法
+	if tg.cleanup == nil && taskCleanup == nil && tg.feed == nil {
+		return nil
+	}
+	return &resultCleaner{cleanup: tg.cleanup, taskCleanup: taskCleanup, discarded: make(map[*TaskResult]struct{})}
+}
+
+// discard 清理被丢弃的任务结果`result`
+func (rc *resultCleaner) discard(result *TaskResult) {
+	// 已交付给调用方的任务结果可能仍在使用中，不再清理
+	if rc == nil || result.result == nil || result.cached || result.delivered.Load() {
+		return
+	}
+	rc.mu.Lock()
+	cleanup, has := rc.taskCleanup[result.fNO]
+	if !has {
+		cleanup = rc.cleanup
+	}
+	if cleanup == nil {
+		rc.mu.Unlock()
+		return
+	}
+	if _, has = rc.discarded[result]; has {
+		rc.mu.Unlock()
+		return
+	}
+	rc.discarded[result] = struct{}{}
+	rc.mu.Unlock()
+
+	result.discarded.Store(true)
+	if err := cleanup(result.result); err != nil {
+		rc.mu.Lock()
+		rc.errs = append(rc.errs, &TaskError{FNO: result.fNO, Name: result.name, Err: fmt.Errorf("cleanup: %w", err)})
+		rc.mu.Unlock()
+	}
+}
+
+// add 记录运行期间添加的任务`task`的清理方法
+func (rc *resultCleaner) add(task *Task) {
+	if rc == nil || task.cleanup == nil {
+		return
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.taskCleanup == nil {
+		rc.taskCleanup = make(map[uint32]CleanupFunc)
+	}
+	rc.taskCleanup[task.fNO] = task.cleanup
+}
+
+// outcome 任务方法的一次调用的执行结果
+type outcome struct {
+	result interface{}
+	err    error
+}
+
+// discardLater 任务`task`的执行被放弃(如，超时或被看门狗放弃)时，待其从`done`返回执行结果后再清理
+func (rc *resultCleaner) discardLater(task *Task, done <-chan outcome) {
+	if rc == nil {
+		return
+	}
+	go func() {
+		o := <-done
+		rc.discard(newTaskResult(task, o.result, o.err))
+	}()
+}
+
+// discardAll 任务组执行失败或被取消时，清理所有已收集的任务结果`results`
+func (rc *resultCleaner) discardAll(results map[uint32]*TaskResult) {
+	if rc == nil {
+		return
+	}
+	for _, result := range results {
+		rc.discard(result)
+	}
+}
+
+// report 将清理情况记录在运行统计`stats`中
+func (rc *resultCleaner) report(stats *RunStats) {
+	if rc == nil {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	stats.Discarded = len(rc.discarded)
+	stats.CleanupErrors = append([]error(nil), rc.errs...)
+}
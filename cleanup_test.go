@@ -0,0 +1,143 @@
+package taskgroup_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+// resource 持有资源的任务结果
+type resource struct {
+	closed atomic.Bool
+}
+
+func (r *resource) Close() error {
+	r.closed.Store(true)
+	return nil
+}
+
+func TestTaskGroupRun_cleanup(t *testing.T) {
+	errTask, errCleanup := errors.New("decode failed"), errors.New("close failed")
+	testCases := []struct {
+		failing bool
+		closed  bool
+	}{
+		{false, false},
+		{true, true},
+	}
+	for _, testCase := range testCases {
+		var (
+			resources [4]resource
+			cleaned   atomic.Int32
+		)
+		open := func(fNO uint32, err error) taskgroup.TaskFunc {
+			return func() (interface{}, error) { return &resources[fNO], err }
+		}
+		var err3 error
+		if testCase.failing {
+			err3 = errTask
+		}
+		tg := taskgroup.NewTaskGroup(taskgroup.WithSequential(), taskgroup.WithCleanup(nil)).AddTask(
+			taskgroup.NewTask(1, open(1, nil), true),
+			taskgroup.NewTask(2, open(2, nil), true, taskgroup.WithTaskCleanup(func(result interface{}) error {
+				cleaned.Add(1)
+				return errCleanup
+			})),
+			taskgroup.NewTask(3, open(3, err3), true),
+		)
+		_, err := tg.Run()
+		if (err != nil) != testCase.failing || (err != nil && !errors.Is(err, errTask)) {
+			t.Fatalf("failing=%v, err=%+v", testCase.failing, err)
+		}
+		// 任务组执行失败时，已收集的结果与失败任务的结果均被清理，清理失败不影响任务组的错误信息
+		if resources[1].closed.Load() != testCase.closed || resources[3].closed.Load() != testCase.closed ||
+			resources[2].closed.Load() || cleaned.Load() != taskgroup.If(testCase.closed, int32(1), int32(0)).(int32) {
+			t.Errorf("failing=%v, closed=[%v %v %v], cleaned=%d", testCase.failing,
+				resources[1].closed.Load(), resources[2].closed.Load(), resources[3].closed.Load(), cleaned.Load())
+		}
+		stats := tg.Stats()
+		if testCase.failing && (stats.Discarded != 3 || len(stats.CleanupErrors) != 1 || !errors.Is(stats.CleanupErrors[0], errCleanup)) {
+			t.Errorf("stats=%+v", stats)
+		}
+	}
+}
+
+func TestTaskGroupRun_cleanupDiscarded(t *testing.T) {
+	var kept, dropped resource
+	tg := taskgroup.NewTaskGroup(taskgroup.WithSequential(), taskgroup.WithCleanup(nil)).AddTask(
+		taskgroup.NewTask(1, func() (interface{}, error) { return &dropped, nil }, true),
+		taskgroup.NewTask(2, func() (interface{}, error) { return nil, errors.New("decode failed") }, true),
+	)
+	results, err := tg.Run()
+	if err == nil {
+		t.Fatal("expected err")
+	}
+	// 已被清理的任务结果不再返回已释放的资源
+	if result := results[1]; !dropped.closed.Load() || !result.Discarded() || result.Result() != nil {
+		t.Errorf("closed=%v, discarded=%v, result=%+v", dropped.closed.Load(), result.Discarded(), result.Result())
+	}
+
+	results, err = taskgroup.NewTaskGroup(taskgroup.WithCleanup(nil)).AddTask(
+		taskgroup.NewTask(1, func() (interface{}, error) { return &kept, nil }, true),
+	).Run()
+	if err != nil || results[1].Discarded() || results[1].Result() != &kept || kept.closed.Load() {
+		t.Errorf("results=%+v, err=%+v", results, err)
+	}
+}
+
+func TestTaskGroupRun_cleanupAbandoned(t *testing.T) {
+	testCases := []struct {
+		name string
+		opt  taskgroup.Option
+		task taskgroup.TaskOption
+	}{
+		{"timeout", nil, taskgroup.WithTimeout(10 * time.Millisecond)},
+		{"watchdog", taskgroup.WithWatchdog(10*time.Millisecond, func(taskgroup.HungTask) {}, true), nil},
+	}
+	for _, testCase := range testCases {
+		var res resource
+		tg := taskgroup.NewTaskGroup(taskgroup.WithCleanup(nil), testCase.opt).AddTask(
+			taskgroup.NewTask(1, func() (interface{}, error) {
+				time.Sleep(50 * time.Millisecond)
+				return &res, nil
+			}, false, testCase.task),
+		)
+		results, _ := tg.Run()
+		if results[1] != nil && results[1].Result() != nil {
+			t.Fatalf("case=%s, result=%+v", testCase.name, results[1].Result())
+		}
+		// 被放弃的执行结束后，其结果被清理
+		deadline := time.Now().Add(time.Second)
+		for !res.closed.Load() && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if !res.closed.Load() {
+			t.Errorf("case=%s, abandoned result not cleaned", testCase.name)
+		}
+	}
+}
+
+func TestTaskGroupRun_cleanupDelivered(t *testing.T) {
+	var (
+		delivered resource
+		errTask   = errors.New("task 2 err")
+	)
+	tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(2), taskgroup.WithCleanup(nil))
+	future := tg.Submit(taskgroup.NewTask(1, func() (interface{}, error) { return &delivered, nil }, false))
+	tg.AddTask(taskgroup.NewTask(2, func() (interface{}, error) {
+		<-future.Done()
+		return nil, errTask
+	}, true))
+	if _, err := tg.Run(); !errors.Is(err, errTask) {
+		t.Fatalf("err=%+v", err)
+	}
+
+	// 已通过句柄交付的任务结果不会被清理
+	result := future.Result()
+	if delivered.closed.Load() || result.Discarded() || result.Result() != &delivered {
+		t.Errorf("closed=%v, discarded=%v", delivered.closed.Load(), result.Discarded())
+	}
+}
@@ -0,0 +1,70 @@
+package taskgroup
+
+import "time"
+
+// Clock 表示时间源，与计时相关的功能均通过其获取时间，以便于测试时注入可控的时钟
+type Clock interface {
+	// Now 获取当前时间
+	Now() time.Time
+	// After 在经过`d`时长后，向返回的`channel`中发送当时的时间
+	After(d time.Duration) <-chan time.Time
+	// NewTicker 创建一个每经过`d`时长，向其`channel`中发送当时时间的计时器
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker 表示由[Clock]创建的周期性计时器
+type Ticker interface {
+	// C 获取接收周期性时间的`channel`
+	C() <-chan time.Time
+	// Stop 停止计时器
+	Stop()
+}
+
+// systemClock 基于系统时间的时钟
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+// systemTicker 基于系统时间的周期性计时器
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t systemTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// SystemClock 系统时钟，未指定时钟时的默认实现
+var SystemClock Clock = systemClock{}
+
+// WithGroupClock 指定任务组运行时使用的时钟`clock`(如，运行统计、落后任务检测及事件记录等)，默认为[SystemClock]
+func WithGroupClock(clock Clock) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.clock = clock
+	}
+}
+
+// runClock 获取任务组运行时使用的时钟
+func (tg *TaskGroup) runClock() Clock {
+	if tg.clock == nil {
+		return SystemClock
+	}
+	return tg.clock
+}
@@ -0,0 +1,198 @@
+package taskgroup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultOutputLimit 命令标准输出与标准错误各自默认的最大捕获字节数
+const DefaultOutputLimit = 1 << 20
+
+// commandWaitDelay 命令被终止后，等待其输出`pipe`关闭的最长时间
+const commandWaitDelay = time.Second
+
+// CommandResult 表示命令任务(见[CommandTask])的执行结果
+type CommandResult struct {
+	Stdout    []byte        // 捕获的标准输出
+	Stderr    []byte        // 捕获的标准错误
+	Truncated bool          // 输出是否因超过上限而被截断，见[WithOutputLimit]
+	ExitCode  int           // 退出码，命令未能启动或被信号终止时为-1
+	Duration  time.Duration // 执行耗时
+}
+
+// ExitError 表示命令以非0的退出码结束
+type ExitError struct {
+	Name     string // 命令名称
+	ExitCode int    // 退出码
+	Stderr   []byte // 捕获的标准错误
+}
+
+func (e *ExitError) Error() string {
+	msg := fmt.Sprintf("command %s exited with code %d", e.Name, e.ExitCode)
+	if stderr := strings.TrimSpace(string(e.Stderr)); stderr != "" {
+		msg += ": " + stderr
+	}
+	return msg
+}
+
+// CommandOption 命令任务的可选项
+type CommandOption func(*command)
+
+// WithCommandDir 指定命令的工作目录`dir`，默认为当前进程的工作目录
+func WithCommandDir(dir string) CommandOption {
+	return func(c *command) {
+		if c == nil {
+			return
+		}
+		c.dir = dir
+	}
+}
+
+// WithCommandEnv 指定命令额外的环境变量`env`(形如"KEY=value")，命令将继承当前进程的环境变量
+func WithCommandEnv(env ...string) CommandOption {
+	return func(c *command) {
+		if c == nil {
+			return
+		}
+		c.env = append(c.env, env...)
+	}
+}
+
+// WithCommandStdin 指定命令的标准输入`stdin`
+//
+// NOTEs: `stdin`仅能被读取一次，任务组重复运行时，需重新创建任务
+func WithCommandStdin(stdin io.Reader) CommandOption {
+	return func(c *command) {
+		if c == nil {
+			return
+		}
+		c.stdin = stdin
+	}
+}
+
+// WithOutputLimit 指定标准输出与标准错误各自的最大捕获字节数`limit`，超出的部分将被丢弃，不大于0时，表示不限制，默认为[DefaultOutputLimit]
+func WithOutputLimit(limit int) CommandOption {
+	return func(c *command) {
+		if c == nil {
+			return
+		}
+		c.outputLimit = limit
+	}
+}
+
+// WithCommandMustSuccess 指定命令任务必须执行成功
+func WithCommandMustSuccess() CommandOption {
+	return func(c *command) {
+		if c == nil {
+			return
+		}
+		c.mustSuccess = true
+	}
+}
+
+// WithCommandTaskOptions 指定命令任务的其他任务选项`opts`(如，[WithTimeout]、[WithTags]等)，任务名称默认为命令名称
+func WithCommandTaskOptions(opts ...TaskOption) CommandOption {
+	return func(c *command) {
+		if c == nil {
+			return
+		}
+		c.taskOpts = append(c.taskOpts, opts...)
+	}
+}
+
+// command 表示一个外部命令
+type command struct {
+	name        string
+	args        []string
+	dir         string
+	env         []string
+	stdin       io.Reader
+	outputLimit int
+	mustSuccess bool
+	taskOpts    []TaskOption
+}
+
+// CommandTask 创建一个执行外部命令`name`(参数为`args`)的任务，执行结果为[*CommandResult]，
+// 命令以非0的退出码结束时，任务将以[*ExitError]失败(执行结果仍可获取)，
+// 任务组被取消或任务执行超时(见[WithTimeout])时，将终止命令所在的整个进程组(仅`unix`)
+func CommandTask(fNO uint32, name string, args []string, opts ...CommandOption) *Task {
+	c := &command{name: name, args: args, outputLimit: DefaultOutputLimit}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	return NewContextTask(fNO, c.run, c.mustSuccess, append([]TaskOption{WithName(name)}, c.taskOpts...)...)
+}
+
+// run 执行命令，直至命令结束或`ctx`被取消
+func (c *command) run(ctx context.Context) (interface{}, error) {
+	var (
+		cmd    = exec.CommandContext(ctx, c.name, c.args...)
+		stdout = &limitedBuffer{limit: c.outputLimit}
+		stderr = &limitedBuffer{limit: c.outputLimit}
+	)
+	cmd.Dir, cmd.Stdin, cmd.Stdout, cmd.Stderr = c.dir, c.stdin, stdout, stderr
+	if len(c.env) > 0 {
+		cmd.Env = append(os.Environ(), c.env...)
+	}
+	cmd.WaitDelay = commandWaitDelay
+	killProcessGroup(cmd)
+
+	startedAt := time.Now()
+	err := cmd.Run()
+	result := &CommandResult{
+		Stdout:    stdout.Bytes(),
+		Stderr:    stderr.Bytes(),
+		Truncated: stdout.truncated || stderr.truncated,
+		ExitCode:  -1,
+		Duration:  time.Since(startedAt),
+	}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		return result, context.Cause(ctx)
+	case errors.As(err, &exitErr):
+		return result, &ExitError{Name: c.name, ExitCode: result.ExitCode, Stderr: result.Stderr}
+	case err != nil:
+		return result, fmt.Errorf("command %s: %w", c.name, err)
+	}
+	return result, nil
+}
+
+// limitedBuffer 仅保留前`limit`个字节的缓冲区，超出的部分将被丢弃，以避免命令因输出被阻塞
+//
+// NOTEs: 不可内嵌`bytes.Buffer`，否则`io.Copy`将通过其`ReadFrom`绕过上限
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit <= 0 {
+		return b.buf.Write(p)
+	}
+	if remaining := b.limit - b.buf.Len(); len(p) > remaining {
+		b.buf.Write(p[:If(remaining > 0, remaining, 0).(int)])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// Bytes 获取缓冲区中的数据
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package taskgroup
+
+import "os/exec"
+
+// killProcessGroup 非`unix`平台上，命令被取消时，仅终止命令本身
+func killProcessGroup(cmd *exec.Cmd) {}
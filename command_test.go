@@ -0,0 +1,69 @@
+//go:build unix
+
+package taskgroup_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestCommandTask(t *testing.T) {
+	dir := t.TempDir()
+	results, err := taskgroup.NewTaskGroup().AddTask(
+		taskgroup.CommandTask(1, "sh", []string{"-c", `echo "$GREETING $(pwd)"; cat`},
+			taskgroup.WithCommandEnv("GREETING=hello"), taskgroup.WithCommandDir(dir),
+			taskgroup.WithCommandStdin(strings.NewReader("from stdin")), taskgroup.WithCommandMustSuccess()),
+		taskgroup.CommandTask(2, "sh", []string{"-c", "echo oops >&2; exit 3"}),
+		taskgroup.CommandTask(3, "sh", []string{"-c", "printf 0123456789"}, nil, taskgroup.WithOutputLimit(4)),
+		taskgroup.CommandTask(4, "taskgroup-no-such-command", nil),
+	).Run()
+	if err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+
+	result := results[1].Result().(*taskgroup.CommandResult)
+	if string(result.Stdout) != "hello "+dir+"\nfrom stdin" || result.ExitCode != 0 || results[1].Name() != "sh" {
+		t.Errorf("result=%+v", result)
+	}
+
+	var exitErr *taskgroup.ExitError
+	if !errors.As(results[2].Error(), &exitErr) || exitErr.ExitCode != 3 || !strings.Contains(exitErr.Error(), "oops") {
+		t.Errorf("err=%+v", results[2].Error())
+	}
+	if result := results[2].Result().(*taskgroup.CommandResult); result.ExitCode != 3 || string(result.Stderr) != "oops\n" {
+		t.Errorf("result=%+v", result)
+	}
+
+	if result := results[3].Result().(*taskgroup.CommandResult); string(result.Stdout) != "0123" || !result.Truncated {
+		t.Errorf("result=%+v", result)
+	}
+
+	if err := results[4].Error(); err == nil || errors.As(err, &exitErr) {
+		t.Errorf("err=%+v", err)
+	}
+}
+
+func TestCommandTask_cancel(t *testing.T) {
+	// 命令派生的子进程持有输出`pipe`，仅终止命令本身时，需等待子进程结束
+	newTask := func(fNO uint32, opts ...taskgroup.TaskOption) *taskgroup.Task {
+		return taskgroup.CommandTask(fNO, "sh", []string{"-c", "sleep 30 & wait"}, taskgroup.WithCommandTaskOptions(opts...))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	startedAt := time.Now()
+	_, err := taskgroup.NewTaskGroup().AddTask(newTask(1)).RunContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(startedAt) > 500*time.Millisecond {
+		t.Errorf("err=%+v, elapsed=%v", err, time.Since(startedAt))
+	}
+
+	results, err := taskgroup.NewTaskGroup().AddTask(newTask(1, taskgroup.WithTimeout(50*time.Millisecond))).Run()
+	if err != nil || !errors.Is(results[1].Error(), taskgroup.ErrTaskTimeout) {
+		t.Errorf("err=%+v, task err=%+v", err, results[1].Error())
+	}
+}
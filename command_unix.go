@@ -0,0 +1,16 @@
+//go:build unix
+
+package taskgroup
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroup 使命令在独立的进程组中运行，命令被取消时，终止整个进程组(包括其派生的子进程)
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,233 @@
+package taskgroup
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrDependencyFailed 任务所依赖的任务执行失败，见[WithDependencies]
+var ErrDependencyFailed = errors.New("taskgroup: dependency failed")
+
+// WithDependencies 指定任务所依赖的任务编号`fNOs`，任务将在其依赖的任务均执行成功后才开始执行，
+// 任一依赖执行失败时，任务将以[ErrDependencyFailed]失败而不再执行；已从检查点恢复的依赖视为已满足，
+// 被丢弃(见[WithMaxQueuedTasks])的依赖视为执行失败，未被选中(见[WithLabelSelector])或不存在的依赖以[ErrTaskNotFound]视为执行失败
+//
+// 任务仅在其依赖均执行结束后才会被分发给`worker`，等待依赖的任务不会占用`worker`及其类别、标签的并发额度；
+// 依赖成环时，任务组将运行失败
+func WithDependencies(fNOs ...uint32) TaskOption {
+	return func(t *Task) {
+		if t == nil {
+			return
+		}
+		t.dependencies = append(t.dependencies, fNOs...)
+	}
+}
+
+// dependency 任务在单次运行中的执行状态
+type dependency struct {
+	finished bool
+	err      error
+}
+
+// dependencyTracker 任务组单次运行期间的任务依赖
+type dependencyTracker struct {
+	mu        sync.Mutex
+	tasks     map[uint32]*dependency
+	recovered map[uint32]*TaskResult // 无需执行的任务的结果
+}
+
+// newDependencyTracker 跟踪待执行的任务`tasks`之间的依赖，`recovered`为无需执行的任务(如，已从检查点恢复或被丢弃)的结果，
+// 没有任务存在依赖，且运行期间不再添加任务(`feeding`为`false`，见[ShedBlock])时，返回`nil`
+func newDependencyTracker(tasks []*Task, recovered map[uint32]*TaskResult, feeding bool) (*dependencyTracker, error) {
+	if !hasDependencies(tasks) && !feeding {
+		return nil, nil
+	}
+	if cycle := dependencyCycle(tasks); cycle != nil {
+		return nil, fmt.Errorf("Run: dependency cycle %v", cycle)
+	}
+	dt := &dependencyTracker{tasks: make(map[uint32]*dependency, len(tasks)), recovered: recovered}
+	for _, task := range tasks {
+		dt.tasks[task.fNO] = new(dependency)
+	}
+	for _, task := range tasks {
+		dt.settleMissing(task)
+	}
+	return dt, nil
+}
+
+// settleMissing 任务`task`的依赖不在本次运行中时，视为已执行结束，调用方需持有锁或独占访问
+func (dt *dependencyTracker) settleMissing(task *Task) {
+	for _, fNO := range task.dependencies {
+		if _, has := dt.tasks[fNO]; has {
+			continue
+		}
+		dep := &dependency{finished: true, err: ErrTaskNotFound}
+		if result, has := dt.recovered[fNO]; has {
+			dep.err = result.err
+		}
+		dt.tasks[fNO] = dep
+	}
+}
+
+// add 跟踪运行期间添加的任务`task`的依赖，其依赖需已添加，否则视为执行失败，依赖自身时，视为依赖成环
+func (dt *dependencyTracker) add(task *Task) {
+	if dt == nil {
+		return
+	}
+
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	dep := new(dependency)
+	for _, fNO := range task.dependencies {
+		if fNO == task.fNO {
+			dep.finished, dep.err = true, fmt.Errorf("dependency cycle %v", []uint32{fNO, fNO})
+		}
+	}
+	dt.settleMissing(task)
+	dt.tasks[task.fNO] = dep
+}
+
+func hasDependencies(tasks []*Task) bool {
+	for _, task := range tasks {
+		if len(task.dependencies) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyCycle 查找任务`tasks`之间的依赖环，返回环上的任务编号，不存在时，返回`nil`
+func dependencyCycle(tasks []*Task) []uint32 {
+	deps := make(map[uint32][]uint32, len(tasks))
+	for _, task := range tasks {
+		deps[task.fNO] = task.dependencies
+	}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	var (
+		states = make(map[uint32]int, len(tasks))
+		path   []uint32
+		visit  func(fNO uint32) []uint32
+	)
+	visit = func(fNO uint32) []uint32 {
+		switch states[fNO] {
+		case visiting:
+			for i := range path {
+				if path[i] == fNO {
+					return append(append([]uint32(nil), path[i:]...), fNO)
+				}
+			}
+		case visited:
+			return nil
+		}
+		states[fNO] = visiting
+		path = append(path, fNO)
+		for _, dep := range deps[fNO] {
+			if _, has := deps[dep]; !has {
+				continue
+			}
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		states[fNO] = visited
+		return nil
+	}
+	for _, task := range tasks {
+		if cycle := visit(task.fNO); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// ready 任务`task`所依赖的任务是否均已执行结束
+func (dt *dependencyTracker) ready(task *Task) bool {
+	if dt == nil {
+		return true
+	}
+
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	for _, fNO := range task.dependencies {
+		if dep, has := dt.tasks[fNO]; has && !dep.finished {
+			return false
+		}
+	}
+	return true
+}
+
+// failed 获取任务`task`所依赖的任务中首个执行失败的错误信息，依赖均执行成功时，返回`nil`
+func (dt *dependencyTracker) failed(task *Task) error {
+	if dt == nil {
+		return nil
+	}
+
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	for _, fNO := range task.dependencies {
+		if dep, has := dt.tasks[fNO]; has && dep.err != nil {
+			return fmt.Errorf("%w: task %d: %w", ErrDependencyFailed, fNO, dep.err)
+		}
+	}
+	return nil
+}
+
+// finish 记录任务的执行结果`result`
+func (dt *dependencyTracker) finish(result *TaskResult) {
+	if dt == nil {
+		return
+	}
+
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	if dep, has := dt.tasks[result.fNO]; has {
+		dep.finished, dep.err = true, result.err
+	}
+}
+
+// sortDependencies 在保持任务`tasks`原有顺序的前提下，将被依赖的任务排在依赖其的任务之前，存在依赖环时，环上的任务保持原有顺序
+func sortDependencies(tasks []*Task) {
+	if !hasDependencies(tasks) {
+		return
+	}
+	pending := make(map[uint32]bool, len(tasks))
+	for _, task := range tasks {
+		pending[task.fNO] = true
+	}
+	sorted := make([]*Task, 0, len(tasks))
+	for len(sorted) < len(tasks) {
+		placed := false
+		for _, task := range tasks {
+			if !pending[task.fNO] || !dependenciesSettled(task, pending) {
+				continue
+			}
+			pending[task.fNO] = false
+			sorted = append(sorted, task)
+			placed = true
+			break
+		}
+		if !placed { // 剩余的任务之间存在依赖环
+			for _, task := range tasks {
+				if pending[task.fNO] {
+					sorted = append(sorted, task)
+				}
+			}
+			break
+		}
+	}
+	copy(tasks, sorted)
+}
+
+func dependenciesSettled(task *Task, pending map[uint32]bool) bool {
+	for _, fNO := range task.dependencies {
+		if pending[fNO] {
+			return false
+		}
+	}
+	return true
+}
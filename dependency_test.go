@@ -0,0 +1,162 @@
+package taskgroup_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_dependencies(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []uint32
+	)
+	newTask := func(fNO uint32, err error, deps ...uint32) *taskgroup.Task {
+		return taskgroup.NewTask(fNO, func() (interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, fNO)
+			return fNO, err
+		}, false, taskgroup.WithDependencies(deps...))
+	}
+
+	// 4 -> 3 -> 1, 4 -> 2
+	for _, opts := range [][]taskgroup.Option{{taskgroup.WithWorkerNums(1)}, {taskgroup.WithSequential()}} {
+		order = nil
+		results, err := taskgroup.NewTaskGroup(opts...).AddTask(
+			newTask(4, nil, 3, 2), newTask(3, nil, 1), newTask(2, nil), newTask(1, nil),
+		).Run()
+		if err != nil || len(results) != 4 {
+			t.Fatalf("results=%d, err=%+v", len(results), err)
+		}
+		position := make(map[uint32]int)
+		for i, fNO := range order {
+			position[fNO] = i
+		}
+		if position[1] > position[3] || position[3] > position[4] || position[2] > position[4] {
+			t.Errorf("order=%v", order)
+		}
+	}
+
+	// 依赖执行失败时，任务不再执行
+	errTask := errors.New("task 1 err")
+	order = nil
+	results, err := taskgroup.NewTaskGroup().AddTask(newTask(1, errTask), newTask(2, nil, 1), newTask(3, nil)).Run()
+	if err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+	if err := results[2].Error(); !errors.Is(err, taskgroup.ErrDependencyFailed) || !errors.Is(err, errTask) || len(order) != 2 {
+		t.Errorf("err=%+v, order=%v", err, order)
+	}
+
+	// 依赖成环(含依赖自身)时，任务组运行失败
+	for _, opts := range [][]taskgroup.Option{nil, {taskgroup.WithSequential()}} {
+		for _, tasks := range [][]*taskgroup.Task{{newTask(1, nil, 2), newTask(2, nil, 1)}, {newTask(1, nil, 1)}} {
+			done := make(chan error, 1)
+			go func() {
+				_, err := taskgroup.NewTaskGroup(opts...).AddTask(tasks...).Run()
+				done <- err
+			}()
+			select {
+			case err := <-done:
+				if err == nil {
+					t.Errorf("tasks=%d, want dependency cycle err", len(tasks))
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("tasks=%d, cycle not detected", len(tasks))
+			}
+		}
+	}
+}
+
+func TestTaskGroupRun_dependenciesLimited(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []uint32
+	)
+	newTask := func(fNO uint32, opts ...taskgroup.TaskOption) *taskgroup.Task {
+		return taskgroup.NewTask(fNO, func() (interface{}, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, fNO)
+			return fNO, nil
+		}, true, opts...)
+	}
+
+	// 等待依赖的任务排在前面时，不会占满类别的协程或标签的并发额度
+	var (
+		cpu = []taskgroup.TaskOption{taskgroup.WithClass(taskgroup.ClassCPU)}
+		db  = []taskgroup.TaskOption{taskgroup.WithTags("db")}
+	)
+	testCases := []struct {
+		opts                          []taskgroup.Option
+		dependentOpts, dependencyOpts []taskgroup.TaskOption
+	}{
+		{[]taskgroup.Option{taskgroup.WithClassWorkers(taskgroup.ClassCPU, 1)}, cpu, cpu},
+		{[]taskgroup.Option{taskgroup.WithTagLimit("db", 1)}, db, db},
+		{[]taskgroup.Option{taskgroup.WithWorkerNums(1)}, nil, nil},
+		// 其他类别的任务等待依赖时，不会预留受限标签的并发额度
+		{
+			[]taskgroup.Option{taskgroup.WithTagLimit("db", 1)},
+			[]taskgroup.TaskOption{taskgroup.WithClass(taskgroup.ClassIO), taskgroup.WithTags("db")},
+			[]taskgroup.TaskOption{taskgroup.WithClass(taskgroup.ClassCPU), taskgroup.WithTags("db")},
+		},
+	}
+	for i, testCase := range testCases {
+		order = nil
+		tg := taskgroup.NewTaskGroup(testCase.opts...).AddTask(
+			newTask(2, append([]taskgroup.TaskOption{taskgroup.WithDependencies(1)}, testCase.dependentOpts...)...),
+			newTask(1, testCase.dependencyOpts...),
+		)
+		done := make(chan error, 1)
+		go func() {
+			_, err := tg.Run()
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err != nil || len(order) != 2 || order[0] != 1 {
+				t.Errorf("case=%d, order=%v, err=%+v", i, order, err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("case=%d, deadlocked", i)
+		}
+	}
+}
+
+func TestTaskGroupRun_dependenciesNotRun(t *testing.T) {
+	executed := make(map[uint32]bool)
+	newTask := func(fNO uint32, opts ...taskgroup.TaskOption) *taskgroup.Task {
+		return taskgroup.NewTask(fNO, func() (interface{}, error) {
+			executed[fNO] = true
+			return fNO, nil
+		}, false, opts...)
+	}
+
+	// 被丢弃的依赖视为执行失败
+	results, err := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(1), taskgroup.WithMaxQueuedTasks(2, taskgroup.ShedReject)).AddTask(
+		newTask(3), newTask(2, taskgroup.WithDependencies(1)), newTask(1),
+	).Run()
+	if err != nil || !results[1].Shed() {
+		t.Fatalf("results=%+v, err=%+v", results, err)
+	}
+	if err := results[2].Error(); !errors.Is(err, taskgroup.ErrDependencyFailed) || !errors.Is(err, taskgroup.ErrQueueFull) || executed[2] {
+		t.Errorf("err=%+v, executed=%v", err, executed)
+	}
+
+	// 未被选中的依赖视为执行失败
+	executed = make(map[uint32]bool)
+	results, err = taskgroup.NewTaskGroup(taskgroup.WithLabelSelector(map[string]string{"env": "prod"})).AddTask(
+		newTask(1, taskgroup.WithLabels(map[string]string{"env": "test"})),
+		newTask(2, taskgroup.WithLabels(map[string]string{"env": "prod"}), taskgroup.WithDependencies(1)),
+	).Run()
+	if err != nil || len(results) != 1 {
+		t.Fatalf("results=%+v, err=%+v", results, err)
+	}
+	if err := results[2].Error(); !errors.Is(err, taskgroup.ErrDependencyFailed) || !errors.Is(err, taskgroup.ErrTaskNotFound) || len(executed) != 0 {
+		t.Errorf("err=%+v, executed=%v", err, executed)
+	}
+}
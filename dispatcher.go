@@ -0,0 +1,233 @@
+package taskgroup
+
+import (
+	"context"
+	"sync"
+)
+
+// WithTagLimit 限制任务组中带有标签`tag`的任务的并发量上限为`n`，`n`为0时，表示不限制
+//
+// 同一任务带有多个受限的标签时，需所有标签的并发量均未达上限，方可执行
+func WithTagLimit(tag string, n uint32) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		if n == 0 {
+			delete(tg.tagLimits, tag)
+			return
+		}
+		if tg.tagLimits == nil {
+			tg.tagLimits = make(map[string]uint32)
+		}
+		tg.tagLimits[tag] = n
+	}
+}
+
+// dispatcher 表示任务分发器，`workers`通过其获取待执行的任务
+type dispatcher interface {
+	// next 获取下一个待执行的任务，当无任务可执行或`ctx`被取消时，返回`false`
+	next(ctx context.Context) (*Task, bool)
+	// done 通知分发器任务`task`已执行结束
+	done(task *Task)
+}
+
+// newDispatcher 按照任务组的配置创建任务分发器，并将所有待执行的任务`tasks`发送至分发器中，`deps`为任务之间的依赖
+func (tg *TaskGroup) newDispatcher(ctx context.Context, tasks []*Task, deps *dependencyTracker) dispatcher {
+	if len(tg.tagLimits) > 0 || deps != nil {
+		return newPendingDispatcher(ctx, tasks, tg.tagLimits, deps)
+	}
+
+	ch := make(chan *Task, len(tasks))
+	for _, task := range tasks {
+		ch <- task
+	}
+	close(ch)
+	return chanDispatcher(ch)
+}
+
+// chanDispatcher 基于`channel`的任务分发器，任务按照发送的顺序依次分发
+type chanDispatcher chan *Task
+
+func (d chanDispatcher) next(ctx context.Context) (*Task, bool) {
+	task, ok := <-d
+	if !ok || ctx.Err() != nil {
+		return nil, false
+	}
+	return task, true
+}
+
+func (chanDispatcher) done(*Task) {}
+
+// pendingDispatcher 仅分发可执行的任务的分发器，即，所有标签的并发量均未达上限，且所依赖的任务均已执行结束
+//
+// 当排在前面的任务因标签并发已达上限或依赖还未执行结束而无法执行时，将跳过该任务，优先分发其后可执行的任务，避免队头阻塞
+type pendingDispatcher struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	pending []*Task
+	limits  map[string]uint32
+	running map[string]uint32 // 各标签执行中的任务数
+	deps    *dependencyTracker
+	stopped bool
+
+	feeding bool   // 是否仍在接收运行期间添加的任务，见[ShedBlock]
+	max     uint32 // 待执行任务数的上限
+	putting int    // 正在添加的任务数
+}
+
+func newPendingDispatcher(ctx context.Context, tasks []*Task, limits map[string]uint32, deps *dependencyTracker) *pendingDispatcher {
+	d := &pendingDispatcher{
+		pending: append(make([]*Task, 0, len(tasks)), tasks...),
+		limits:  limits,
+		running: make(map[string]uint32, len(limits)),
+		deps:    deps,
+	}
+	d.cond = sync.NewCond(&d.mu)
+	// `ctx`被取消时，唤醒所有等待中的`workers`
+	context.AfterFunc(ctx, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.stopped = true
+		d.cond.Broadcast()
+	})
+	return d
+}
+
+func (d *pendingDispatcher) next(ctx context.Context) (*Task, bool) {
+	return d.take(ctx, func(*Task) bool { return true })
+}
+
+// take 获取下一个与`match`匹配的待执行任务，无匹配的任务时，返回`false`
+func (d *pendingDispatcher) take(ctx context.Context, match func(task *Task) bool) (*Task, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for {
+		if d.stopped || ctx.Err() != nil {
+			return nil, false
+		}
+		var (
+			matched bool
+			waiting map[string]struct{} // 排在前面的不匹配的任务所需的受限标签
+		)
+		for i, task := range d.pending {
+			if !match(task) {
+				// 排在前面的可执行任务(如，其他类别的任务)优先获得受限标签的并发额度，避免其被饿死；
+				// 仍在等待依赖的任务不预留额度，以免其所依赖的任务无法执行
+				if !d.deps.ready(task) {
+					continue
+				}
+				for _, tag := range task.limitTags {
+					if _, has := d.limits[tag]; has {
+						if waiting == nil {
+							waiting = make(map[string]struct{})
+						}
+						waiting[tag] = struct{}{}
+					}
+				}
+				continue
+			}
+			matched = true
+			if !d.eligible(task) || hasAnyTag(task, waiting) {
+				continue
+			}
+			d.pending = append(d.pending[:i], d.pending[i+1:]...)
+			for _, tag := range task.limitTags {
+				d.running[tag]++
+			}
+			if d.feeding { // 唤醒等待添加任务的协程
+				d.cond.Broadcast()
+			}
+			return task, true
+		}
+		// 仍在接收运行期间添加的任务时，等待新的任务
+		if !matched && !d.feeding && d.putting == 0 {
+			return nil, false
+		}
+		d.cond.Wait()
+	}
+}
+
+// eligible 任务`task`的所有标签的并发量均未达上限，且所依赖的任务均已执行结束时，方可执行
+func (d *pendingDispatcher) eligible(task *Task) bool {
+	for _, tag := range task.limitTags {
+		if limit, has := d.limits[tag]; has && d.running[tag] >= limit {
+			return false
+		}
+	}
+	return d.deps.ready(task)
+}
+
+// hasAnyTag 任务`task`是否带有`tags`中的任一标签
+func hasAnyTag(task *Task, tags map[string]struct{}) bool {
+	for _, tag := range task.limitTags {
+		if _, has := tags[tag]; has {
+			return true
+		}
+	}
+	return false
+}
+
+// feed 接收运行期间添加的任务，待执行的任务数不超过`max`，见[ShedBlock]
+func (d *pendingDispatcher) feed(max uint32) {
+	d.feeding, d.max = true, max
+}
+
+// full 待执行的任务数是否已达上限
+func (d *pendingDispatcher) full() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return uint32(len(d.pending)) >= d.max
+}
+
+// beginPut 登记一个正在添加的任务，需随后调用[pendingDispatcher.put]或[pendingDispatcher.endPut]
+func (d *pendingDispatcher) beginPut() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.putting++
+}
+
+// endPut 结束添加任务
+func (d *pendingDispatcher) endPut() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.putting--
+	d.cond.Broadcast()
+}
+
+// put 添加任务`task`，待执行的任务数已达上限时，阻塞直至有任务被取走，分发器已停止时，任务将不再执行
+func (d *pendingDispatcher) put(task *Task) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for !d.stopped && uint32(len(d.pending)) >= d.max {
+		d.cond.Wait()
+	}
+	if !d.stopped {
+		d.pending = append(d.pending, task)
+	}
+	d.putting--
+	d.cond.Broadcast()
+}
+
+// closeFeed 不再接收运行期间添加的任务
+func (d *pendingDispatcher) closeFeed() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.feeding = false
+	d.cond.Broadcast()
+}
+
+// done 任务执行结束时，释放其标签的并发额度，并唤醒等待中的`workers`(如，依赖其的任务已可执行)
+func (d *pendingDispatcher) done(task *Task) {
+	if len(task.limitTags) == 0 && d.deps == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, tag := range task.limitTags {
+		d.running[tag]--
+	}
+	d.cond.Broadcast()
+}
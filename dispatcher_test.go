@@ -0,0 +1,53 @@
+package taskgroup_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_tagLimit(t *testing.T) {
+	const (
+		dbTag    = "db"
+		dbLimit  = 3
+		taskNums = 20
+	)
+	var running, maxRunning atomic.Int32
+	tasks := make([]*taskgroup.Task, 0, taskNums)
+	for i := 1; i <= taskNums; i++ {
+		if i%2 == 0 {
+			tasks = append(tasks, taskgroup.NewTask(uint32(i), task2ReturnSuccessWrapper(uint32(i), false), false))
+			continue
+		}
+		tasks = append(tasks, taskgroup.NewTask(uint32(i), func() (interface{}, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				if old := maxRunning.Load(); n <= old || maxRunning.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return nil, nil
+		}, true, taskgroup.WithTags(dbTag)))
+	}
+
+	tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(8), taskgroup.WithTagLimit(dbTag, dbLimit))
+	results, err := tg.AddTask(tasks...).Run()
+	if err != nil || len(results) != taskNums {
+		t.Fatalf("results=%+v, err=%+v", results, err)
+	}
+	if got := maxRunning.Load(); got > dbLimit {
+		t.Errorf("max running db tasks=%d, expected<=%d", got, dbLimit)
+	}
+
+	stats := tg.Stats()
+	if stats == nil || stats.Tasks != taskNums || stats.Succeeded != taskNums || stats.TagStats[dbTag].Tasks != taskNums/2 {
+		t.Errorf("stats=%+v", stats)
+	}
+	if tagStats := stats.TagStats[dbTag]; tagStats.MaxWait <= 0 || tagStats.TotalWait < tagStats.MaxWait {
+		t.Errorf("tag stats=%+v", tagStats)
+	}
+}
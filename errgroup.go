@@ -0,0 +1,179 @@
+package taskgroup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ErrGroup 与`golang.org/x/sync/errgroup.Group`语义一致的任务组，以便于从`errgroup`迁移，
+// 但函数将复用(共享)已有的协程执行，而不是为每个函数都创建一个新的协程
+//
+// 零值的[ErrGroup]可直接使用，不限制并发量，且不会因函数执行失败而取消任何操作
+type ErrGroup struct {
+	cancel func(error)
+
+	wg  sync.WaitGroup
+	sem chan struct{} // 并发量限制，为`nil`时，表示不限制
+
+	errOnce sync.Once
+	err     error
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []func()
+	workers int // 协程数
+	active  int // 待执行或执行中的函数数
+
+	results map[uint32]*TaskResult // 由[ErrGroup.GoTask]添加的任务的执行结果
+}
+
+// WithContext 创建一个[ErrGroup]，以及由`ctx`派生的上下文，当首个函数执行失败或首次[ErrGroup.Wait]返回时，
+// 派生的上下文将被取消，取消的原因为首个函数的错误信息
+func WithContext(ctx context.Context) (*ErrGroup, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &ErrGroup{cancel: cancel}, ctx
+}
+
+// Go 在协程上执行函数`f`，当并发量已达上限时，将阻塞直至可执行，首个执行失败的函数的错误信息将由[ErrGroup.Wait]返回
+func (g *ErrGroup) Go(f func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.dispatch(f)
+}
+
+// TryGo 当并发量未达上限时，在协程上执行函数`f`，并返回`true`，否则，返回`false`
+func (g *ErrGroup) TryGo(f func() error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.dispatch(f)
+	return true
+}
+
+// SetLimit 限制同时执行的函数数至多为`n`，为负数时，表示不限制
+//
+// NOTEs: 仍有函数在执行时，修改限制将会`panic`
+func (g *ErrGroup) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	if len(g.sem) != 0 {
+		panic(fmt.Errorf("ErrGroup: modify limit while %v goroutines in the group are still active", len(g.sem)))
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Wait 等待所有函数执行完成，并返回首个执行失败的函数的错误信息
+func (g *ErrGroup) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(g.err)
+	}
+	return g.err
+}
+
+// GoTask 同[ErrGroup.Go]，但会收集任务`fNO`的执行结果，可通过[ErrGroup.WaitResults]获取，
+// 任务执行失败时，同样将取消派生的上下文
+//
+// NOTEs: 出现了相同的任务(任务的标识相等)，将会`panic`
+func (g *ErrGroup) GoTask(fNO uint32, f TaskFunc) {
+	g.mu.Lock()
+	if _, has := g.results[fNO]; has {
+		g.mu.Unlock()
+		panic(fmt.Sprintf("GoTask: Already have the same Task %d", fNO))
+	}
+	if g.results == nil {
+		g.results = make(map[uint32]*TaskResult)
+	}
+	g.results[fNO] = nil // 占位，避免重复添加
+	g.mu.Unlock()
+
+	g.Go(func() error {
+		result, err := f()
+		if err != nil {
+			err = &TaskError{FNO: fNO, MustSuccess: true, Attempt: 1, Err: err}
+		}
+		g.mu.Lock()
+		g.results[fNO] = &TaskResult{fNO: fNO, result: result, err: err, attempt: 1}
+		g.mu.Unlock()
+		return err
+	})
+}
+
+// WaitResults 同[ErrGroup.Wait]，并返回由[ErrGroup.GoTask]添加的所有任务的执行结果
+func (g *ErrGroup) WaitResults() (Results, error) {
+	err := g.Wait()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	results := make(Results, len(g.results))
+	for fNO, result := range g.results {
+		results[fNO] = result
+	}
+	return results, err
+}
+
+// dispatch 将函数`f`交由空闲的协程执行，无空闲的协程时，创建一个新的协程
+func (g *ErrGroup) dispatch(f func() error) {
+	g.wg.Add(1)
+	job := func() {
+		defer g.done()
+		if err := f(); err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				if g.cancel != nil {
+					g.cancel(err)
+				}
+			})
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.cond == nil {
+		g.cond = sync.NewCond(&g.mu)
+	}
+	g.active++
+	if g.workers >= g.active {
+		g.queue = append(g.queue, job)
+		g.cond.Signal()
+		return
+	}
+	g.workers++
+	go g.worker(job)
+}
+
+func (g *ErrGroup) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+// worker 依次执行分发的函数，所有函数均执行完成后退出
+func (g *ErrGroup) worker(job func()) {
+	for {
+		job()
+
+		g.mu.Lock()
+		g.active--
+		// 仍有函数在执行时，等待其可能添加的后续函数
+		for len(g.queue) == 0 && g.active > 0 {
+			g.cond.Wait()
+		}
+		if len(g.queue) == 0 {
+			g.workers--
+			g.cond.Broadcast()
+			g.mu.Unlock()
+			return
+		}
+		job, g.queue = g.queue[0], g.queue[1:]
+		g.mu.Unlock()
+	}
+}
@@ -0,0 +1,229 @@
+package taskgroup_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+// group `errgroup.Group`与`taskgroup.ErrGroup`的公共方法
+type group interface {
+	Go(f func() error)
+	TryGo(f func() error) bool
+	SetLimit(n int)
+	Wait() error
+}
+
+// groupImpls 一致性测试的实现，`taskgroup.ErrGroup`的行为应与`errgroup.Group`完全一致
+var groupImpls = []struct {
+	name        string
+	zero        func() group
+	withContext func(ctx context.Context) (group, context.Context)
+}{
+	{
+		"errgroup",
+		func() group { return new(errgroup.Group) },
+		func(ctx context.Context) (group, context.Context) { return errgroup.WithContext(ctx) },
+	},
+	{
+		"taskgroup",
+		func() group { return new(taskgroup.ErrGroup) },
+		func(ctx context.Context) (group, context.Context) { return taskgroup.WithContext(ctx) },
+	},
+}
+
+func TestErrGroup_zeroGroup(t *testing.T) {
+	err1, err2 := errors.New("errgroup_test: 1"), errors.New("errgroup_test: 2")
+	testCases := []struct {
+		errs []error
+	}{
+		{errs: []error{}},
+		{errs: []error{nil}},
+		{errs: []error{err1}},
+		{errs: []error{err1, nil}},
+		{errs: []error{err1, nil, err2}},
+	}
+	for _, impl := range groupImpls {
+		for _, testCase := range testCases {
+			g := impl.zero()
+			var firstErr error
+			for i, err := range testCase.errs {
+				err := err
+				g.Go(func() error { return err })
+				if firstErr == nil && err != nil {
+					firstErr = err
+				}
+				// 函数依次执行完成，首个错误始终被保留
+				if gErr := g.Wait(); gErr != firstErr {
+					t.Errorf("%s: after %T.Go(func() error { return err }) for err in %v\n"+
+						"g.Wait() = %v; want %v", impl.name, g, testCase.errs[:i+1], gErr, firstErr)
+				}
+			}
+		}
+	}
+}
+
+func TestErrGroup_withContext(t *testing.T) {
+	errDoom := errors.New("group_test: doomed")
+	testCases := []struct {
+		errs []error
+		want error
+	}{
+		{want: nil},
+		{errs: []error{nil}, want: nil},
+		{errs: []error{errDoom}, want: errDoom},
+		{errs: []error{errDoom, nil}, want: errDoom},
+	}
+	for _, impl := range groupImpls {
+		for _, testCase := range testCases {
+			g, ctx := impl.withContext(context.Background())
+			for _, err := range testCase.errs {
+				err := err
+				g.Go(func() error { return err })
+			}
+			if err := g.Wait(); err != testCase.want {
+				t.Errorf("%s: after %T.Go(func() error { return err }) for err in %v\n"+
+					"g.Wait() = %v; want %v", impl.name, g, testCase.errs, err, testCase.want)
+			}
+			// `Wait`返回后，派生的上下文总会被取消
+			select {
+			case <-ctx.Done():
+			default:
+				t.Errorf("%s: after %T.Go(func() error { return err }) for err in %v\n"+
+					"ctx.Done() was not closed", impl.name, g, testCase.errs)
+			}
+			if testCase.want != nil && context.Cause(ctx) != testCase.want {
+				t.Errorf("%s: context.Cause(ctx) = %v; want %v", impl.name, context.Cause(ctx), testCase.want)
+			}
+		}
+	}
+}
+
+func TestErrGroup_cancelOnError(t *testing.T) {
+	errDoom := errors.New("doomed")
+	for _, impl := range groupImpls {
+		g, ctx := impl.withContext(context.Background())
+		g.Go(func() error {
+			<-ctx.Done() // 首个函数执行失败后，派生的上下文被取消
+			return ctx.Err()
+		})
+		g.Go(func() error { return errDoom })
+		if err := g.Wait(); err != errDoom {
+			t.Errorf("%s: g.Wait() = %v; want %v", impl.name, err, errDoom)
+		}
+	}
+}
+
+func TestErrGroup_tryGo(t *testing.T) {
+	for _, impl := range groupImpls {
+		g := impl.zero()
+		n := 42
+		g.SetLimit(42)
+		ch := make(chan struct{})
+		fn := func() error {
+			ch <- struct{}{}
+			return nil
+		}
+		for i := 0; i < n; i++ {
+			if !g.TryGo(fn) {
+				t.Fatalf("%s: TryGo should succeed but got fail at %d-th call.", impl.name, i)
+			}
+		}
+		if g.TryGo(fn) {
+			t.Fatalf("%s: TryGo is expected to fail but succeeded.", impl.name)
+		}
+		go func() {
+			for i := 0; i < n; i++ {
+				<-ch
+			}
+		}()
+		g.Wait()
+
+		if !g.TryGo(fn) {
+			t.Fatalf("%s: TryGo should success but got fail after all goroutines.", impl.name)
+		}
+		go func() { <-ch }()
+		g.Wait()
+
+		// 限制为0时，`TryGo`均会失败
+		g.SetLimit(0)
+		for i := 0; i < 1<<10; i++ {
+			if g.TryGo(fn) {
+				t.Fatalf("%s: TryGo should fail", impl.name)
+			}
+		}
+		g.Wait()
+	}
+}
+
+func TestErrGroup_goLimit(t *testing.T) {
+	const limit = 10
+	for _, impl := range groupImpls {
+		g := impl.zero()
+		g.SetLimit(limit)
+		var active, maxActive int32
+		for i := 0; i <= 1<<10; i++ {
+			g.Go(func() error {
+				n := atomic.AddInt32(&active, 1)
+				for {
+					prev := atomic.LoadInt32(&maxActive)
+					if n <= prev || atomic.CompareAndSwapInt32(&maxActive, prev, n) {
+						break
+					}
+				}
+				if n > limit {
+					return errors.New("saw too many active goroutines")
+				}
+				time.Sleep(time.Microsecond)
+				atomic.AddInt32(&active, -1)
+				return nil
+			})
+		}
+		if err := g.Wait(); err != nil || maxActive > limit {
+			t.Errorf("%s: maxActive=%d, err=%v", impl.name, maxActive, err)
+		}
+	}
+}
+
+func TestErrGroup_setLimitPanic(t *testing.T) {
+	for _, impl := range groupImpls {
+		g := impl.zero()
+		g.SetLimit(1)
+		release := make(chan struct{})
+		g.Go(func() error {
+			<-release
+			return nil
+		})
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: SetLimit should panic while goroutines are active", impl.name)
+				}
+			}()
+			g.SetLimit(2)
+		}()
+		close(release)
+		g.Wait()
+	}
+}
+
+func TestErrGroup_results(t *testing.T) {
+	g, _ := taskgroup.WithContext(context.Background())
+	g.GoTask(1, task2ReturnSuccessWrapper(1, false))
+	g.GoTask(2, task4ReturnSuccessWrapper(2, false))
+	g.GoTask(3, task3ReturnFailWrapper(3, false))
+	results, err := g.WaitResults()
+	var taskErr *taskgroup.TaskError
+	if !errors.As(err, &taskErr) || taskErr.FNO != 3 {
+		t.Fatalf("err=%+v", err)
+	}
+	if len(results) != 3 || len(results.Succeeded()) != 2 || results[3].Error() == nil {
+		t.Errorf("results=%+v", results)
+	}
+}
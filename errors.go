@@ -0,0 +1,32 @@
+package taskgroup
+
+import (
+	"fmt"
+	"time"
+)
+
+// TaskError 表示任务执行失败的错误信息，任务执行失败时，[TaskResult.Error]均为该错误，
+// 任务组因必要成功的任务失败而结束时，[TaskGroup.Run]返回的也为该错误
+//
+// 可通过`errors.Is`或`errors.As`判断任务返回的原始错误
+type TaskError struct {
+	FNO         uint32        // 任务编号(标识)
+	Name        string        // 任务名称
+	MustSuccess bool          // 任务是否必须执行成功
+	Attempt     uint32        // 任务的执行次数
+	StartedAt   time.Time     // 任务(最后一次)开始执行的时间
+	Duration    time.Duration // 任务(最后一次)执行的耗时
+	Err         error         // 任务返回的原始错误
+}
+
+func (e *TaskError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("task %d (%s): %v", e.FNO, e.Name, e.Err)
+	}
+	return fmt.Sprintf("task %d: %v", e.FNO, e.Err)
+}
+
+// Unwrap 获取任务返回的原始错误
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,57 @@
+package taskgroup_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_taskError(t *testing.T) {
+	errTask := errors.New("db unavailable")
+	tasks := []*taskgroup.Task{
+		taskgroup.NewTask(1, task2ReturnSuccessWrapper(1, false), false),
+		taskgroup.NewTask(7, func() (interface{}, error) {
+			time.Sleep(time.Millisecond)
+			return nil, errTask
+		}, true),
+	}
+
+	_, err := taskgroup.NewTaskGroup().AddTask(tasks...).Run()
+	if !errors.Is(err, errTask) {
+		t.Fatalf("err=%+v, expected=%+v", err, errTask)
+	}
+	var taskErr *taskgroup.TaskError
+	if !errors.As(err, &taskErr) {
+		t.Fatalf("err=%T, expected=*taskgroup.TaskError", err)
+	}
+	if taskErr.FNO != 7 || !taskErr.MustSuccess || taskErr.Attempt != 1 || taskErr.StartedAt.IsZero() || taskErr.Duration < time.Millisecond {
+		t.Errorf("task error=%+v", taskErr)
+	}
+	if expected := "task 7: db unavailable"; err.Error() != expected {
+		t.Errorf("err=%q, expected=%q", err.Error(), expected)
+	}
+}
+
+func TestTaskResult_taskError(t *testing.T) {
+	errTask := errors.New("cache miss")
+	results, err := taskgroup.NewTaskGroup().AddTask(
+		taskgroup.NewTask(3, func() (interface{}, error) { return nil, errTask }, false, taskgroup.WithName("warmup")),
+	).Run()
+	if err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+
+	// 非必要成功的任务执行失败时，同样以`*TaskError`包装
+	var taskErr *taskgroup.TaskError
+	if resultErr := results[3].Error(); !errors.As(resultErr, &taskErr) || !errors.Is(resultErr, errTask) {
+		t.Fatalf("err=%+v", resultErr)
+	}
+	if taskErr.FNO != 3 || taskErr.Name != "warmup" || taskErr.MustSuccess || taskErr.Attempt != 1 || taskErr.StartedAt.IsZero() {
+		t.Errorf("task error=%+v", taskErr)
+	}
+	if errs := taskgroup.Results(results).Errors(); len(errs) != 1 || errs[0] != taskErr {
+		t.Errorf("errs=%+v", errs)
+	}
+}
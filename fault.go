@@ -0,0 +1,236 @@
+package taskgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FaultKind 表示注入的故障类型
+type FaultKind uint8
+
+const (
+	// FaultError 使任务返回错误
+	FaultError FaultKind = iota
+	// FaultLatency 使任务延迟执行或延迟返回
+	FaultLatency
+	// FaultPanic 使任务`panic`，其将在任务执行处被恢复，并转换为任务的错误信息
+	FaultPanic
+)
+
+func (k FaultKind) String() string {
+	switch k {
+	case FaultError:
+		return "error"
+	case FaultLatency:
+		return "latency"
+	case FaultPanic:
+		return "panic"
+	default:
+		return fmt.Sprintf("FaultKind(%d)", uint8(k))
+	}
+}
+
+// FaultStage 表示故障的注入时机
+type FaultStage uint8
+
+const (
+	// FaultBeforeCall 在调用任务方法前注入，注入错误或`panic`时，任务方法将不会被调用
+	FaultBeforeCall FaultStage = iota
+	// FaultAfterCall 在调用任务方法后注入，注入错误或`panic`时，任务方法的执行结果将被丢弃
+	FaultAfterCall
+)
+
+// ErrInjectedFault 注入的默认错误，注入的`panic`被恢复后的错误信息同样可通过`errors.Is`判断
+var ErrInjectedFault = errors.New("taskgroup: injected fault")
+
+// Fault 表示一条故障注入规则，任务需满足所有的筛选条件
+type Fault struct {
+	Kind  FaultKind  // 故障类型
+	Stage FaultStage // 注入时机
+
+	FNOs        []uint32          // 按任务编号筛选，为空时，不限制
+	Labels      map[string]string // 按任务的元数据标签筛选(同[WithLabelSelector])，为空时，不限制
+	Probability float64           // 满足筛选条件的任务被注入的概率，不在(0, 1)内时，视为1
+
+	Err     error         // [FaultError]注入的错误，为`nil`时，默认为[ErrInjectedFault]
+	Latency time.Duration // [FaultLatency]注入的延迟
+}
+
+// WithFaultInjection 按照规则`faults`向任务注入故障，用于混沌测试，未指定时，不会有任何额外的开销
+//
+// 任务是否被注入仅取决于随机数种子`seed`、任务编号、执行次数(重试时)及规则，与任务的执行顺序无关，故相同的`seed`可稳定复现，
+// 被注入的故障记录在[TaskResult.Faults]中
+func WithFaultInjection(seed int64, faults ...Fault) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.faultSeed, tg.faults = seed, faults
+	}
+}
+
+// Faults 获取任务被注入的故障(按执行次数依次记录，同一次执行的推测执行不会重复记录)，未被注入时，返回`nil`
+func (tr *TaskResult) Faults() []FaultKind {
+	if tr == nil {
+		return nil
+	}
+	return tr.faults
+}
+
+// injectedPanic 注入的`panic`的值
+type injectedPanic struct {
+	fNO uint32
+}
+
+// faultInjector 任务组单次运行期间的故障注入
+type faultInjector struct {
+	seed   int64
+	faults []Fault
+	clock  Clock
+
+	mu       sync.Mutex
+	injected map[uint32][]injectedFault
+}
+
+// injectedFault 任务在第`attempt`次执行时被注入的第`rule`条规则的故障
+type injectedFault struct {
+	attempt uint32
+	rule    int
+	kind    FaultKind
+}
+
+func (tg *TaskGroup) newFaultInjector() *faultInjector {
+	if len(tg.faults) == 0 {
+		return nil
+	}
+	return &faultInjector{seed: tg.faultSeed, faults: tg.faults, clock: tg.runClock(), injected: make(map[uint32][]injectedFault)}
+}
+
+// call 调用任务`task`的任务方法，并注入其满足筛选条件的故障
+func (fi *faultInjector) call(ctx context.Context, task *Task) (result interface{}, err error) {
+	if fi == nil {
+		return task.call(ctx)
+	}
+
+	var (
+		attempt       = attemptOf(ctx)
+		before, after []int
+	)
+	for i := range fi.faults {
+		if fi.selected(i, task, attempt) {
+			if fi.faults[i].Stage == FaultAfterCall {
+				after = append(after, i)
+			} else {
+				before = append(before, i)
+			}
+		}
+	}
+	if len(before) == 0 && len(after) == 0 {
+		return task.call(ctx)
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			if _, ok := v.(injectedPanic); !ok {
+				panic(v)
+			}
+			result, err = nil, fmt.Errorf("%w: panic", ErrInjectedFault)
+		}
+	}()
+	if err = fi.inject(ctx, task, attempt, before); err != nil {
+		return nil, err
+	}
+	result, err = task.call(ctx)
+	if injectedErr := fi.inject(ctx, task, attempt, after); injectedErr != nil {
+		return nil, injectedErr
+	}
+	return result, err
+}
+
+// selected 任务`task`的第`attempt`次执行是否满足第`i`条规则的筛选条件
+func (fi *faultInjector) selected(i int, task *Task, attempt uint32) bool {
+	fault := &fi.faults[i]
+	if len(fault.FNOs) > 0 {
+		var has bool
+		for _, fNO := range fault.FNOs {
+			if has = fNO == task.fNO; has {
+				break
+			}
+		}
+		if !has {
+			return false
+		}
+	}
+	if !matchLabels(task.labels, fault.Labels) {
+		return false
+	}
+	if fault.Probability <= 0 || fault.Probability >= 1 {
+		return true
+	}
+	return fi.random(i, task.fNO, attempt) < fault.Probability
+}
+
+// random 由随机数种子、规则、任务编号及执行次数确定的[0, 1)内的随机数(`splitmix64`)，重试时将重新抽取
+func (fi *faultInjector) random(i int, fNO, attempt uint32) float64 {
+	x := uint64(fi.seed) ^ uint64(fNO)<<32 ^ uint64(attempt-1)<<16 ^ uint64(i)
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	x ^= x >> 31
+	return float64(x>>11) / (1 << 53)
+}
+
+// inject 向任务`task`的第`attempt`次执行依次注入第`rules`条规则的故障
+func (fi *faultInjector) inject(ctx context.Context, task *Task, attempt uint32, rules []int) error {
+	for _, rule := range rules {
+		fault := &fi.faults[rule]
+		fi.record(task.fNO, injectedFault{attempt, rule, fault.Kind})
+
+		switch fault.Kind {
+		case FaultLatency:
+			select {
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			case <-fi.clock.After(fault.Latency):
+			}
+		case FaultPanic:
+			panic(injectedPanic{task.fNO})
+		default:
+			if fault.Err != nil {
+				return fault.Err
+			}
+			return ErrInjectedFault
+		}
+	}
+	return nil
+}
+
+// record 记录任务`fNO`被注入的故障，同一次执行的推测执行被注入同一故障时，不重复记录
+func (fi *faultInjector) record(fNO uint32, injected injectedFault) {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	for _, recorded := range fi.injected[fNO] {
+		if recorded == injected {
+			return
+		}
+	}
+	fi.injected[fNO] = append(fi.injected[fNO], injected)
+}
+
+// injectedFaults 获取任务`fNO`被注入的故障
+func (fi *faultInjector) injectedFaults(fNO uint32) []FaultKind {
+	if fi == nil {
+		return nil
+	}
+
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	var kinds []FaultKind
+	for _, injected := range fi.injected[fNO] {
+		kinds = append(kinds, injected.kind)
+	}
+	return kinds
+}
@@ -0,0 +1,110 @@
+package taskgroup_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_faultInjection(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	var calls [5]atomic.Int32
+	task := func(fNO uint32, opts ...taskgroup.TaskOption) *taskgroup.Task {
+		return taskgroup.NewTask(fNO, func() (interface{}, error) {
+			calls[fNO].Add(1)
+			return fNO, nil
+		}, false, opts...)
+	}
+	tg := taskgroup.NewTaskGroup(taskgroup.WithFaultInjection(1,
+		taskgroup.Fault{Kind: taskgroup.FaultError, FNOs: []uint32{1}, Err: errUnavailable},
+		taskgroup.Fault{Kind: taskgroup.FaultPanic, Labels: map[string]string{"dep": "cache"}},
+		taskgroup.Fault{Kind: taskgroup.FaultPanic, Stage: taskgroup.FaultAfterCall, FNOs: []uint32{3}},
+		taskgroup.Fault{Kind: taskgroup.FaultLatency, FNOs: []uint32{4}, Latency: 20 * time.Millisecond},
+	)).AddTask(
+		task(1),
+		task(2, taskgroup.WithLabels(map[string]string{"dep": "cache"})),
+		task(3),
+		task(4),
+	)
+	results, err := tg.Run()
+	if err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+
+	testCases := []struct {
+		fNO    uint32
+		faults []taskgroup.FaultKind
+		err    error
+		calls  int32
+	}{
+		{1, []taskgroup.FaultKind{taskgroup.FaultError}, errUnavailable, 0},
+		{2, []taskgroup.FaultKind{taskgroup.FaultPanic}, taskgroup.ErrInjectedFault, 0},
+		{3, []taskgroup.FaultKind{taskgroup.FaultPanic}, taskgroup.ErrInjectedFault, 1},
+		{4, []taskgroup.FaultKind{taskgroup.FaultLatency}, nil, 1},
+	}
+	for _, testCase := range testCases {
+		result := results[testCase.fNO]
+		if !reflect.DeepEqual(result.Faults(), testCase.faults) || !errors.Is(result.Error(), testCase.err) ||
+			(testCase.err == nil) != (result.Error() == nil) || calls[testCase.fNO].Load() != testCase.calls {
+			t.Errorf("fno=%d, faults=%v, err=%+v, calls=%d", testCase.fNO, result.Faults(), result.Error(), calls[testCase.fNO].Load())
+		}
+	}
+}
+
+func TestTaskGroupRun_faultInjectionSeed(t *testing.T) {
+	run := func(seed int64) []uint32 {
+		tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(8), taskgroup.WithFaultInjection(seed,
+			taskgroup.Fault{Kind: taskgroup.FaultError, Probability: 0.3},
+		))
+		for fNO := uint32(1); fNO <= 100; fNO++ {
+			tg.AddTask(taskgroup.NewTask(fNO, task2ReturnSuccessWrapper(fNO, false), false))
+		}
+		results, err := tg.RunResults(context.Background())
+		if err != nil {
+			t.Fatalf("err: %+v", err)
+		}
+		var failed []uint32
+		for _, result := range results.Failed() {
+			failed = append(failed, result.FNO())
+		}
+		return failed
+	}
+
+	// 相同的种子，注入的任务相同
+	failed := run(42)
+	if len(failed) == 0 || len(failed) == 100 || !reflect.DeepEqual(failed, run(42)) {
+		t.Errorf("failed=%v", failed)
+	}
+	if reflect.DeepEqual(failed, run(7)) {
+		t.Errorf("seed 7 failed=%v", failed)
+	}
+}
+
+func TestTaskGroupRun_faultInjectionRetry(t *testing.T) {
+	run := func(seed int64, retries uint32) *taskgroup.TaskResult {
+		results, err := taskgroup.NewTaskGroup(taskgroup.WithFaultInjection(seed,
+			taskgroup.Fault{Kind: taskgroup.FaultError, FNOs: []uint32{1}, Probability: 0.5},
+		)).AddTask(
+			taskgroup.NewTask(1, task2ReturnSuccessWrapper(1, false), false, taskgroup.WithRetries(retries)),
+		).Run()
+		if err != nil {
+			t.Fatalf("err: %+v", err)
+		}
+		return results[1]
+	}
+
+	// 找到首次执行被注入故障的种子
+	seed := int64(0)
+	for ; run(seed, 0).Error() == nil; seed++ {
+	}
+	// 每次重试将重新抽取，故障按执行次数依次记录
+	result := run(seed, 20)
+	if result.Error() != nil || result.Attempt() < 2 || len(result.Faults()) != int(result.Attempt())-1 {
+		t.Errorf("seed=%d, attempt=%d, faults=%v, err=%+v", seed, result.Attempt(), result.Faults(), result.Error())
+	}
+}
@@ -0,0 +1,172 @@
+package taskgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// errInvalidTask 提交的任务为`nil`或没有任务方法
+var errInvalidTask = errors.New("Submit: task is nil or has no task function")
+
+// Future 表示任务的句柄，可在任务组运行期间(其他协程中)单独等待该任务的执行结果，而无需等待整个任务组运行结束
+//
+// 句柄以任务组首次运行时该任务的执行结果为准，已通过句柄交付的任务结果不会再被清理(见[WithCleanup])，需由调用方自行释放其持有的资源
+type Future struct {
+	fNO  uint32
+	once sync.Once
+	done chan struct{}
+
+	result *TaskResult
+	err    error // 任务未执行(如，任务组执行失败或被取消)或必要成功的任务执行失败时的错误信息
+}
+
+func newFuture(fNO uint32) *Future {
+	return &Future{fNO: fNO, done: make(chan struct{})}
+}
+
+// Submit 同[TaskGroup.AddTask]，添加任务`task`，并返回其句柄
+//
+// NOTEs: 出现了相同的任务(任务的标识相等)，将会`panic`
+func (tg *TaskGroup) Submit(task *Task) *Future {
+	if tg == nil || task == nil || !task.runnable() {
+		f := newFuture(0)
+		f.resolve(nil, errInvalidTask)
+		return f
+	}
+
+	// 先登记句柄，以便运行期间添加的任务(见[ShedBlock])执行结束时即可完成句柄
+	f := newFuture(task.fNO)
+	tg.futures.add(f)
+	tg.AddTask(task)
+	return f
+}
+
+// futureSet 由[TaskGroup.Submit]添加的任务的句柄，任务组运行期间仍可添加(见[ShedBlock])
+type futureSet struct {
+	mu      sync.Mutex
+	futures map[uint32]*Future
+}
+
+// add 登记句柄`f`，已有相同任务的句柄时，保留已有的句柄
+func (fs *futureSet) add(f *Future) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if _, has := fs.futures[f.fNO]; has {
+		return
+	}
+	if fs.futures == nil {
+		fs.futures = make(map[uint32]*Future)
+	}
+	fs.futures[f.fNO] = f
+}
+
+// get 获取任务`fNO`的句柄，不存在时，返回`nil`
+func (fs *futureSet) get(fNO uint32) *Future {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.futures[fNO]
+}
+
+// resolve 记录任务的执行结果，仅首次有效
+func (f *Future) resolve(result *TaskResult, err error) {
+	if f == nil {
+		return
+	}
+	f.once.Do(func() {
+		if result != nil {
+			result.delivered.Store(true)
+		}
+		f.result, f.err = result, err
+		close(f.done)
+	})
+}
+
+// settleFutures 任务组运行结束时，以执行结果`results`完成所有的句柄，不在执行结果中的任务，以任务组的错误信息`err`完成
+func (tg *TaskGroup) settleFutures(results map[uint32]*TaskResult, err error) {
+	tg.futures.mu.Lock()
+	defer tg.futures.mu.Unlock()
+	for fNO, f := range tg.futures.futures {
+		if result, has := results[fNO]; has && result != nil {
+			f.resolve(result, nil)
+			continue
+		}
+		if err != nil {
+			f.resolve(nil, fmt.Errorf("task %d: %w: %w", fNO, ErrTaskNotFound, err))
+			continue
+		}
+		f.resolve(nil, fmt.Errorf("task %d: %w", fNO, ErrTaskNotFound))
+	}
+}
+
+// FNO 获取任务的唯一标识号
+func (f *Future) FNO() uint32 {
+	return f.fNO
+}
+
+// Done 任务执行完成(或确定不会执行)时，返回的`channel`将被关闭
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Result 获取任务的执行结果，任务还未完成时，返回`nil`
+func (f *Future) Result() *TaskResult {
+	select {
+	case <-f.done:
+		return f.result
+	default:
+		return nil
+	}
+}
+
+// Wait 等待并返回任务的执行结果(任务的执行状态见[TaskResult.Error])
+//
+// 任务未执行时，返回[ErrTaskNotFound]，必要成功的任务执行失败(使任务组执行失败)时，同时返回其执行结果与[*TaskError]，
+// `ctx`先被取消时，返回其取消的原因
+func (f *Future) Wait(ctx context.Context) (*TaskResult, error) {
+	select {
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	case <-f.done:
+		return f.result, f.err
+	}
+}
+
+// AwaitAll 等待所有的任务`futures`执行完成，并返回其执行结果
+//
+// 存在未执行或执行失败的必要成功任务时，返回其中首个任务的错误信息(同[Future.Wait])，`ctx`先被取消时，返回其取消的原因
+func AwaitAll(ctx context.Context, futures ...*Future) (Results, error) {
+	results := make(Results, len(futures))
+	var firstErr error
+	for _, f := range futures {
+		select {
+		case <-ctx.Done():
+			return results, context.Cause(ctx)
+		case <-f.done:
+		}
+		if f.err != nil && firstErr == nil {
+			firstErr = f.err
+		}
+		if f.result != nil {
+			results[f.fNO] = f.result
+		}
+	}
+	return results, firstErr
+}
+
+// AwaitAny 等待任务`futures`中首个完成的任务，并返回其执行结果(同[Future.Wait])，`futures`为空时，等待直至`ctx`被取消
+func AwaitAny(ctx context.Context, futures ...*Future) (*TaskResult, error) {
+	cases := make([]reflect.SelectCase, 0, len(futures)+1)
+	cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+	for _, f := range futures {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(f.done)})
+	}
+	chosen, _, _ := reflect.Select(cases)
+	if chosen == 0 {
+		return nil, context.Cause(ctx)
+	}
+	f := futures[chosen-1]
+	return f.result, f.err
+}
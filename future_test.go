@@ -0,0 +1,82 @@
+package taskgroup_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestFuture(t *testing.T) {
+	release := make(chan struct{})
+	tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(2))
+	fast := tg.Submit(taskgroup.NewTask(1, task2ReturnSuccessWrapper(1, false), true))
+	slow := tg.Submit(taskgroup.NewTask(2, func() (interface{}, error) {
+		<-release
+		return "slow", nil
+	}, true))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := tg.Run()
+		done <- err
+	}()
+
+	// 任务1的结果可在任务组运行结束前获取
+	result, err := fast.Wait(context.Background())
+	if err != nil || result.FNO() != 1 || result.Error() != nil {
+		t.Fatalf("result=%+v, err=%+v", result, err)
+	}
+	if slow.Result() != nil {
+		t.Fatalf("slow result=%+v", slow.Result())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err = slow.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err=%+v", err)
+	}
+	if result, err = taskgroup.AwaitAny(context.Background(), slow, fast); err != nil || result.FNO() != 1 {
+		t.Errorf("any result=%+v, err=%+v", result, err)
+	}
+
+	close(release)
+	results, err := taskgroup.AwaitAll(context.Background(), fast, slow)
+	if err != nil || len(results) != 2 || results[2].Result() != "slow" {
+		t.Errorf("all results=%+v, err=%+v", results, err)
+	}
+	if err = <-done; err != nil {
+		t.Errorf("run err=%+v", err)
+	}
+}
+
+func TestFuture_notRun(t *testing.T) {
+	tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(1))
+	failed := tg.Submit(taskgroup.NewTask(1, task3ReturnFailWrapper(1, false), true))
+	skipped := tg.Submit(taskgroup.NewTask(2, task2ReturnSuccessWrapper(2, false), false))
+	invalid := tg.Submit(nil)
+
+	_, runErr := tg.Run()
+	if runErr == nil {
+		t.Fatal("expected run err")
+	}
+	// 执行失败的必要成功任务以其执行结果及错误信息完成
+	var taskErr *taskgroup.TaskError
+	result, err := failed.Wait(context.Background())
+	if result == nil || result.FNO() != 1 || !errors.As(err, &taskErr) || taskErr.FNO != 1 || err != runErr ||
+		errors.Is(err, taskgroup.ErrTaskNotFound) || !errors.Is(result.Error(), taskErr.Err) {
+		t.Errorf("result=%+v, err=%+v", result, err)
+	}
+	// 任务组执行失败后，未执行的任务以任务组的错误信息完成
+	if _, err = skipped.Wait(context.Background()); !errors.Is(err, taskgroup.ErrTaskNotFound) || !errors.Is(err, runErr) {
+		t.Errorf("err=%+v", err)
+	}
+	results, err := taskgroup.AwaitAll(context.Background(), skipped, failed)
+	if !errors.Is(err, taskgroup.ErrTaskNotFound) || len(results) != 1 || results[1] != result {
+		t.Errorf("all results=%+v, err=%+v", results, err)
+	}
+	if _, err := invalid.Wait(context.Background()); err == nil {
+		t.Error("expected invalid task err")
+	}
+}
@@ -0,0 +1,70 @@
+package taskgroup
+
+import "context"
+
+// RunHandle 表示异步运行中的任务组，见[TaskGroup.Start]
+type RunHandle struct {
+	tg     *TaskGroup
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+
+	results map[uint32]*TaskResult
+	err     error
+}
+
+// Start 同[TaskGroup.Run]，但在后台异步运行任务组，并立即返回运行的句柄
+func (tg *TaskGroup) Start() *RunHandle {
+	return tg.StartContext(context.Background())
+}
+
+// StartContext 同[TaskGroup.RunContext]，但在后台异步运行任务组，并立即返回运行的句柄
+func (tg *TaskGroup) StartContext(ctx context.Context) *RunHandle {
+	ctx, cancel := context.WithCancelCause(ctx)
+	h := &RunHandle{tg: tg, cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(h.done)
+		defer cancel(nil)
+		h.results, h.err = tg.RunContext(ctx)
+	}()
+	return h
+}
+
+// Wait 等待任务组运行结束，并返回任务的执行结果，同[TaskGroup.Run]
+func (h *RunHandle) Wait() (map[uint32]*TaskResult, error) {
+	<-h.done
+	return h.results, h.err
+}
+
+// Cancel 以原因`cause`取消任务组的运行，所有还未启动的任务将不再执行，[RunHandle.Wait]将返回`cause`，
+// `cause`为`nil`时，返回`context.Canceled`，任务组已运行结束时，调用无效
+func (h *RunHandle) Cancel(cause error) {
+	h.cancel(cause)
+}
+
+// Done 任务组运行结束时，返回的`channel`将被关闭
+func (h *RunHandle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Progress 获取任务组运行进度的快照，任务组还未开始执行任务时，返回零值
+func (h *RunHandle) Progress() Progress {
+	select {
+	case <-h.done:
+		if stats := h.tg.Stats(); stats != nil {
+			return Progress{Tasks: stats.Tasks, Succeeded: stats.Succeeded, Failed: stats.Failed}
+		}
+		return Progress{}
+	default:
+	}
+	if h.tg == nil {
+		return Progress{}
+	}
+
+	h.tg.statsMu.Lock()
+	live := h.tg.live
+	h.tg.statsMu.Unlock()
+	if live == nil {
+		return Progress{}
+	}
+	return live.progress()
+}
@@ -0,0 +1,68 @@
+package taskgroup_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupStart(t *testing.T) {
+	var (
+		release = make(chan struct{})
+		started atomic.Int32
+	)
+	tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(2))
+	for fNO := uint32(1); fNO <= 6; fNO++ {
+		tg.AddTask(taskgroup.NewTask(fNO, func() (interface{}, error) {
+			started.Add(1)
+			<-release
+			return nil, nil
+		}, false))
+	}
+
+	h := tg.Start()
+	for h.Progress().Running != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if progress := h.Progress(); progress.Tasks != 6 || progress.Succeeded != 0 {
+		t.Errorf("progress=%+v", progress)
+	}
+	select {
+	case <-h.Done():
+		t.Fatal("done before cancel")
+	default:
+	}
+
+	// 取消后，还未启动的任务不再执行
+	errStop := errors.New("stop")
+	h.Cancel(errStop)
+	close(release)
+	if _, err := h.Wait(); !errors.Is(err, errStop) {
+		t.Errorf("err=%+v", err)
+	}
+	<-h.Done()
+	if n := started.Load(); n != 2 {
+		t.Errorf("started=%d", n)
+	}
+	if progress := h.Progress(); progress.Running != 0 || progress.Succeeded != 2 {
+		t.Errorf("final progress=%+v", progress)
+	}
+}
+
+func TestTaskGroupStart_wait(t *testing.T) {
+	h := taskgroup.NewTaskGroup().AddTask(
+		taskgroup.NewTask(1, task2ReturnSuccessWrapper(1, false), true),
+		taskgroup.NewTask(2, task4ReturnSuccessWrapper(2, false), true),
+	).Start()
+	results, err := h.Wait()
+	if err != nil || len(results) != 2 {
+		t.Errorf("results=%+v, err=%+v", results, err)
+	}
+	h.Cancel(errors.New("too late")) // 运行结束后取消无效
+	if _, err = h.Wait(); err != nil {
+		t.Errorf("err=%+v", err)
+	}
+}
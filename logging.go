@@ -0,0 +1,137 @@
+package taskgroup
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// LogLevels 表示各类任务日志的级别
+type LogLevels struct {
+	Start   slog.Level // 任务开始执行
+	Finish  slog.Level // 任务执行成功
+	Failure slog.Level // 任务执行失败
+	Cancel  slog.Level // 任务因任务组被取消而未执行
+}
+
+// DefaultLogLevels 默认的任务日志级别
+var DefaultLogLevels = LogLevels{Start: slog.LevelDebug, Finish: slog.LevelInfo, Failure: slog.LevelError, Cancel: slog.LevelWarn}
+
+// loggerKey 上下文中任务日志记录器的键
+type loggerKey struct{}
+
+// WithLogger 指定任务组的日志记录器`logger`，任务的开始、成功、失败及取消均将被记录，
+// 并带有`fno`、`name`、`worker`、`attempt`、`duration`等属性，级别见[WithLogLevels]，未指定时，不记录任何日志
+//
+// 可感知运行上下文的任务可通过[LoggerFromContext]获取带有任务属性的日志记录器
+func WithLogger(logger *slog.Logger) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.logger = logger
+	}
+}
+
+// WithLogLevels 指定各类任务日志的级别`levels`，默认为[DefaultLogLevels]
+func WithLogLevels(levels LogLevels) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.logLevels = &levels
+	}
+}
+
+// LoggerFromContext 获取任务组(见[WithLogger])提供给任务的日志记录器，其带有任务的属性，未指定时，返回`slog.Default()`
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// taskLogger 任务组单次运行期间的任务日志
+type taskLogger struct {
+	logger *slog.Logger
+	levels LogLevels
+
+	mu          sync.Mutex
+	startedFNOs map[uint32]struct{}
+}
+
+func (tg *TaskGroup) newTaskLogger() *taskLogger {
+	if tg.logger == nil {
+		return nil
+	}
+	levels := DefaultLogLevels
+	if tg.logLevels != nil {
+		levels = *tg.logLevels
+	}
+	return &taskLogger{logger: tg.logger, levels: levels, startedFNOs: make(map[uint32]struct{})}
+}
+
+// taskContext 为任务`task`的本次执行提供带有任务属性(含执行次数)的日志记录器
+func (tl *taskLogger) taskContext(ctx context.Context, task *Task, worker int) context.Context {
+	if tl == nil || task.cf == nil {
+		return ctx
+	}
+	logger := tl.logger.With(slog.Uint64("fno", uint64(task.fNO)), slog.String("name", task.name), slog.Int("worker", worker),
+		slog.Uint64("attempt", uint64(attemptOf(ctx))))
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// started 记录任务`task`开始执行，重试时，每次执行均会记录
+func (tl *taskLogger) started(ctx context.Context, task *Task, worker int) {
+	if tl == nil {
+		return
+	}
+	tl.mu.Lock()
+	tl.startedFNOs[task.fNO] = struct{}{}
+	tl.mu.Unlock()
+	if !tl.logger.Enabled(ctx, tl.levels.Start) {
+		return
+	}
+	tl.logger.LogAttrs(ctx, tl.levels.Start, "task started",
+		slog.Uint64("fno", uint64(task.fNO)), slog.String("name", task.name), slog.Int("worker", worker),
+		slog.Uint64("attempt", uint64(attemptOf(ctx))))
+}
+
+func (tl *taskLogger) finished(ctx context.Context, result *TaskResult, worker int, duration time.Duration) {
+	if tl == nil {
+		return
+	}
+	level, msg := tl.levels.Finish, "task succeeded"
+	if result.err != nil {
+		level, msg = tl.levels.Failure, "task failed"
+	}
+	if !tl.logger.Enabled(ctx, level) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.Uint64("fno", uint64(result.fNO)), slog.String("name", result.name), slog.Int("worker", worker),
+		slog.Duration("duration", duration), slog.Uint64("attempt", uint64(result.attempt)),
+	}
+	if result.err != nil {
+		attrs = append(attrs, slog.String("error", result.err.Error()))
+	}
+	tl.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// cancelled 记录任务组因`cause`被取消后，任务`tasks`中所有未开始执行的任务
+func (tl *taskLogger) cancelled(tasks []*Task, cause error) {
+	if tl == nil || !tl.logger.Enabled(context.Background(), tl.levels.Cancel) {
+		return
+	}
+
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	for _, task := range tasks {
+		if _, has := tl.startedFNOs[task.fNO]; has {
+			continue
+		}
+		tl.logger.LogAttrs(context.Background(), tl.levels.Cancel, "task cancelled",
+			slog.Uint64("fno", uint64(task.fNO)), slog.String("name", task.name), slog.String("cause", cause.Error()))
+	}
+}
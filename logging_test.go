@@ -0,0 +1,140 @@
+package taskgroup_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_logger(t *testing.T) {
+	readLogs := func(buf *bytes.Buffer) []map[string]interface{} {
+		var logs []map[string]interface{}
+		for dec := json.NewDecoder(buf); dec.More(); {
+			var entry map[string]interface{}
+			if err := dec.Decode(&entry); err != nil {
+				t.Fatalf("decode err=%+v", err)
+			}
+			logs = append(logs, entry)
+		}
+		return logs
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	_, err := taskgroup.NewTaskGroup(taskgroup.WithLogger(logger), taskgroup.WithSequential()).AddTask(
+		taskgroup.NewContextTask(1, func(ctx context.Context) (interface{}, error) {
+			taskgroup.LoggerFromContext(ctx).Info("inside")
+			return "ok", nil
+		}, false, taskgroup.WithName("fetch")),
+		taskgroup.NewTask(2, func() (interface{}, error) { return nil, errors.New("task 2 err") }, false),
+	).Run()
+	if err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+
+	var msgs []string
+	for _, entry := range readLogs(&buf) {
+		msgs = append(msgs, entry["msg"].(string))
+		switch entry["msg"] {
+		case "inside":
+			if entry["fno"] != float64(1) || entry["name"] != "fetch" || entry["worker"] != float64(1) {
+				t.Errorf("entry=%+v", entry)
+			}
+		case "task succeeded":
+			if entry["level"] != "INFO" || entry["attempt"] != float64(1) || entry["duration"] == nil {
+				t.Errorf("entry=%+v", entry)
+			}
+		case "task failed":
+			if entry["level"] != "ERROR" || entry["fno"] != float64(2) || entry["error"] != "task 2 err" {
+				t.Errorf("entry=%+v", entry)
+			}
+		}
+	}
+	want := []string{"task started", "inside", "task succeeded", "task started", "task failed"}
+	if len(msgs) != len(want) {
+		t.Fatalf("msgs=%+v", msgs)
+	}
+	for i := range want {
+		if msgs[i] != want[i] {
+			t.Fatalf("msgs=%+v", msgs)
+		}
+	}
+
+	// 必要成功的任务失败后，未开始执行的任务将以`Cancel`级别记录
+	buf.Reset()
+	levels := taskgroup.DefaultLogLevels
+	levels.Start, levels.Cancel = slog.LevelDebug-1, slog.LevelError
+	_, err = taskgroup.NewTaskGroup(taskgroup.WithLogger(logger), taskgroup.WithLogLevels(levels), taskgroup.WithSequential()).AddTask(
+		taskgroup.NewTask(1, func() (interface{}, error) { return nil, errors.New("task 1 err") }, true),
+		taskgroup.NewTask(2, func() (interface{}, error) { return "ok", nil }, false),
+	).Run()
+	if err == nil {
+		t.Fatal("want err")
+	}
+	logs := readLogs(&buf)
+	if len(logs) != 2 || logs[0]["msg"] != "task failed" || logs[1]["msg"] != "task cancelled" ||
+		logs[1]["level"] != "ERROR" || logs[1]["fno"] != float64(2) || logs[1]["cause"] == nil {
+		t.Errorf("logs=%+v", logs)
+	}
+}
+
+func TestTaskGroupRun_loggerAttempt(t *testing.T) {
+	var (
+		buf   bytes.Buffer
+		calls int
+	)
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	_, err := taskgroup.NewTaskGroup(taskgroup.WithLogger(logger)).AddTask(
+		taskgroup.NewContextTask(1, func(ctx context.Context) (interface{}, error) {
+			calls++
+			taskgroup.LoggerFromContext(ctx).Info("inside")
+			if calls == 1 {
+				return nil, errors.New("conn reset")
+			}
+			return "ok", nil
+		}, true, taskgroup.WithRetries(1)),
+	).Run()
+	if err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+
+	// 每次执行均记录开始执行，且任务获取到的日志记录器带有当前的执行次数
+	var got []string
+	for dec := json.NewDecoder(&buf); dec.More(); {
+		var entry struct {
+			Msg     string  `json:"msg"`
+			Attempt float64 `json:"attempt"`
+		}
+		if err = dec.Decode(&entry); err != nil {
+			t.Fatalf("decode err=%+v", err)
+		}
+		got = append(got, fmt.Sprintf("%s#%v", entry.Msg, entry.Attempt))
+	}
+	want := []string{"task started#1", "inside#1", "task started#2", "inside#2", "task succeeded#2"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("logs=%v, want=%v", got, want)
+	}
+}
+
+func TestLoggerFromContext(t *testing.T) {
+	if taskgroup.LoggerFromContext(context.Background()) != slog.Default() {
+		t.Error("want slog.Default()")
+	}
+
+	// 未指定日志记录器时，任务获取到的为`slog.Default()`
+	_, err := taskgroup.NewTaskGroup().AddTask(taskgroup.NewContextTask(1, func(ctx context.Context) (interface{}, error) {
+		if taskgroup.LoggerFromContext(ctx) != slog.Default() {
+			t.Error("want slog.Default()")
+		}
+		return nil, nil
+	}, true)).Run()
+	if err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package taskgroup
+
+import "strconv"
+
+// WithName 指定任务的名称`name`，任务名称将出现在执行结果、错误信息及运行统计中
+func WithName(name string) TaskOption {
+	return func(t *Task) {
+		if t == nil {
+			return
+		}
+		t.name = name
+	}
+}
+
+// WithDescription 指定任务的描述`description`
+func WithDescription(description string) TaskOption {
+	return func(t *Task) {
+		if t == nil {
+			return
+		}
+		t.description = description
+	}
+}
+
+// WithLabels 指定任务的元数据标签`labels`(键值对)，多次指定时将合并，可用以筛选执行结果([Results.Select])及选择待执行的任务([WithLabelSelector])
+//
+// 元数据标签不影响任务的调度，限制并发量的标签见[WithTags]
+func WithLabels(labels map[string]string) TaskOption {
+	return func(t *Task) {
+		if t == nil || len(labels) == 0 {
+			return
+		}
+		if t.labels == nil {
+			t.labels = make(map[string]string, len(labels))
+		}
+		for k, v := range labels {
+			t.labels[k] = v
+		}
+	}
+}
+
+// WithLabelSelector 指定任务组的标签选择器`selector`，运行时仅执行标签与其所有键值对均匹配的任务
+func WithLabelSelector(selector map[string]string) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.selector = selector
+	}
+}
+
+// ident 获取任务的可读标识，如，`1`或`1 (fetch profile)`
+func (t *Task) ident() string {
+	if t.name == "" {
+		return strconv.FormatUint(uint64(t.fNO), 10)
+	}
+	return strconv.FormatUint(uint64(t.fNO), 10) + " (" + t.name + ")"
+}
+
+// selectTasks 筛选出任务`tasks`中与标签选择器匹配的任务
+func (tg *TaskGroup) selectTasks(tasks []*Task) []*Task {
+	if len(tg.selector) == 0 {
+		return tasks
+	}
+
+	selected := make([]*Task, 0, len(tasks))
+	for _, task := range tasks {
+		if matchLabels(task.labels, tg.selector) {
+			selected = append(selected, task)
+		}
+	}
+	return selected
+}
+
+// matchLabels 标签`labels`是否与选择器`selector`的所有键值对均匹配
+func matchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if label, has := labels[k]; !has || label != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Name 获取任务名称
+func (tr *TaskResult) Name() string {
+	if tr == nil {
+		return ""
+	}
+	return tr.name
+}
+
+// Description 获取任务描述
+func (tr *TaskResult) Description() string {
+	if tr == nil {
+		return ""
+	}
+	return tr.description
+}
+
+// Labels 获取任务的元数据标签(副本)
+func (tr *TaskResult) Labels() map[string]string {
+	if tr == nil || tr.labels == nil {
+		return nil
+	}
+
+	labels := make(map[string]string, len(tr.labels))
+	for k, v := range tr.labels {
+		labels[k] = v
+	}
+	return labels
+}
@@ -0,0 +1,62 @@
+package taskgroup_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_metadata(t *testing.T) {
+	newTasks := func() []*taskgroup.Task {
+		return []*taskgroup.Task{
+			taskgroup.NewTask(1, task2ReturnSuccessWrapper(1, false), true,
+				taskgroup.WithName("profile"), taskgroup.WithLabels(map[string]string{"section": "profile"})),
+			taskgroup.NewTask(2, task4ReturnSuccessWrapper(2, false), true,
+				taskgroup.WithName("feed"), taskgroup.WithDescription("recommended feed"), taskgroup.WithLabels(map[string]string{"section": "feed"})),
+			taskgroup.NewTask(3, task3ReturnFailWrapper(3, false), true,
+				taskgroup.WithName("ads"), taskgroup.WithLabels(map[string]string{"section": "feed", "optional": "true"})),
+		}
+	}
+
+	results, err := taskgroup.NewTaskGroup().AddTask(newTasks()...).RunResults(context.Background())
+	if expected := "task 3 (ads): fno: 3, TASK3 err"; err == nil || err.Error() != expected {
+		t.Errorf("err=%+v, expected=%s", err, expected)
+	}
+
+	tg := taskgroup.NewTaskGroup(taskgroup.WithLabelSelector(map[string]string{"section": "feed"})).AddTask(newTasks()...)
+	if _, err = tg.Run(); err == nil { // 任务`3`被选中，且执行失败
+		t.Errorf("expected err")
+	}
+	tg = taskgroup.NewTaskGroup(taskgroup.WithLabelSelector(map[string]string{"section": "profile"})).AddTask(newTasks()...)
+	if results, err = tg.RunResults(context.Background()); err != nil || len(results) != 1 || results[1].Name() != "profile" {
+		t.Errorf("results=%+v, err=%+v", results, err)
+	}
+
+	tg = taskgroup.NewTaskGroup().AddTask(newTasks()[:2]...)
+	if results, err = tg.RunResults(context.Background()); err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+	if feed := results.Select(map[string]string{"section": "feed"}); len(feed) != 1 || feed[2].Description() != "recommended feed" || feed[2].Labels()["section"] != "feed" {
+		t.Errorf("feed=%+v", feed)
+	}
+	// 获取的标签为副本，修改不影响执行结果
+	results[2].Labels()["section"] = "ads"
+	if feed := results.Select(map[string]string{"section": "feed"}); len(feed) != 1 || results[2].Labels()["section"] != "feed" {
+		t.Errorf("labels=%+v", results[2].Labels())
+	}
+}
+
+func TestTaskGroupAddTask_duplicateName(t *testing.T) {
+	defer func() {
+		if r := recover(); fmt.Sprint(r) != "AddTask: Already have the same Task 2 (feed)" {
+			t.Errorf("panic=%+v", r)
+		}
+	}()
+
+	taskgroup.NewTaskGroup().AddTask(
+		taskgroup.NewTask(2, task2ReturnSuccessWrapper(2, false), true),
+		taskgroup.NewTask(2, task4ReturnSuccessWrapper(2, false), true, taskgroup.WithName("feed")),
+	)
+}
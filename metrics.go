@@ -0,0 +1,239 @@
+package taskgroup
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMetricsBuckets 耗时类直方图默认的桶上界(秒)
+var DefaultMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics 任务组运行指标的注册表，可被多个任务组共享，同名任务组的指标将被合并，
+// 其本身即为一个`http.Handler`，以`Prometheus`文本格式输出所有指标，仅依赖标准库
+//
+// 包含的指标如下(均带有`group`标签)：
+//   - taskgroup_tasks_started_total、taskgroup_tasks_succeeded_total、taskgroup_tasks_failed_total、taskgroup_tasks_cancelled_total
+//   - taskgroup_task_duration_seconds、taskgroup_task_queue_wait_seconds
+//   - taskgroup_active_workers
+type Metrics struct {
+	buckets []float64
+
+	mu     sync.Mutex
+	groups map[string]*groupMetrics
+}
+
+// NewMetrics 创建一个指标注册表，`buckets`为耗时类直方图的桶上界(秒)，未指定时，为[DefaultMetricsBuckets]
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultMetricsBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Metrics{buckets: buckets, groups: make(map[string]*groupMetrics)}
+}
+
+// WithMetrics 将任务组以名称`name`注册到指标注册表`metrics`中，`metrics`为`nil`时，不记录指标
+func WithMetrics(metrics *Metrics, name string) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.metrics = metrics.group(name)
+	}
+}
+
+// group 获取名称为`name`的任务组指标，不存在时，创建之
+func (m *Metrics) group(name string) *groupMetrics {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	gm, has := m.groups[name]
+	if !has {
+		gm = &groupMetrics{buckets: m.buckets, duration: newHistogram(m.buckets), queueWait: newHistogram(m.buckets)}
+		m.groups[name] = gm
+	}
+	return gm
+}
+
+// ServeHTTP 以`Prometheus`文本格式输出所有指标
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// WriteTo 将所有指标以`Prometheus`文本格式写入`w`
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	names := make([]string, 0, len(m.groups))
+	snapshots := make(map[string]groupSnapshot, len(m.groups))
+	for name, gm := range m.groups {
+		names = append(names, name)
+		snapshots[name] = gm.snapshot()
+	}
+	m.mu.Unlock()
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	counters := []struct {
+		name, help string
+		value      func(s groupSnapshot) uint64
+	}{
+		{"taskgroup_tasks_started_total", "Number of tasks started.", func(s groupSnapshot) uint64 { return s.started }},
+		{"taskgroup_tasks_succeeded_total", "Number of tasks succeeded.", func(s groupSnapshot) uint64 { return s.succeeded }},
+		{"taskgroup_tasks_failed_total", "Number of tasks failed.", func(s groupSnapshot) uint64 { return s.failed }},
+		{"taskgroup_tasks_cancelled_total", "Number of tasks cancelled before they started.", func(s groupSnapshot) uint64 { return s.cancelled }},
+	}
+	for _, counter := range counters {
+		writeMetricHeader(&buf, counter.name, counter.help, "counter")
+		for _, name := range names {
+			fmt.Fprintf(&buf, "%s{group=%s} %d\n", counter.name, quoteLabel(name), counter.value(snapshots[name]))
+		}
+	}
+	histograms := []struct {
+		name, help string
+		value      func(s groupSnapshot) histogram
+	}{
+		{"taskgroup_task_duration_seconds", "Task execution duration in seconds.", func(s groupSnapshot) histogram { return s.duration }},
+		{"taskgroup_task_queue_wait_seconds", "Time tasks waited in the queue before they started, in seconds.", func(s groupSnapshot) histogram { return s.queueWait }},
+	}
+	for _, h := range histograms {
+		writeMetricHeader(&buf, h.name, h.help, "histogram")
+		for _, name := range names {
+			h.value(snapshots[name]).write(&buf, h.name, quoteLabel(name), m.buckets)
+		}
+	}
+	writeMetricHeader(&buf, "taskgroup_active_workers", "Number of workers currently running.", "gauge")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "taskgroup_active_workers{group=%s} %d\n", quoteLabel(name), snapshots[name].activeWorkers)
+	}
+	return buf.WriteTo(w)
+}
+
+func writeMetricHeader(buf *bytes.Buffer, name, help, typ string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// labelEscaper 转义标签值中的反斜杠、双引号与换行符
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteLabel(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
+
+// histogram 直方图，`counts`为各桶(不累计)的计数，最后一个为`+Inf`桶
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(buckets []float64) histogram {
+	return histogram{counts: make([]uint64, len(buckets)+1)}
+}
+
+func (h *histogram) observe(buckets []float64, value float64) {
+	h.counts[sort.SearchFloat64s(buckets, value)]++
+	h.sum += value
+	h.count++
+}
+
+func (h histogram) write(buf *bytes.Buffer, name, group string, buckets []float64) {
+	var cumulative uint64
+	for i, upper := range buckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(buf, "%s_bucket{group=%s,le=\"%s\"} %d\n", name, group, strconv.FormatFloat(upper, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(buf, "%s_bucket{group=%s,le=\"+Inf\"} %d\n", name, group, h.count)
+	fmt.Fprintf(buf, "%s_sum{group=%s} %s\n", name, group, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(buf, "%s_count{group=%s} %d\n", name, group, h.count)
+}
+
+// groupMetrics 同名任务组的指标
+type groupMetrics struct {
+	buckets []float64
+
+	mu            sync.Mutex
+	started       uint64
+	succeeded     uint64
+	failed        uint64
+	cancelled     uint64
+	activeWorkers int64
+	duration      histogram
+	queueWait     histogram
+}
+
+// groupSnapshot 同名任务组指标的快照
+type groupSnapshot struct {
+	started, succeeded, failed, cancelled uint64
+	activeWorkers                         int64
+	duration, queueWait                   histogram
+}
+
+func (gm *groupMetrics) snapshot() groupSnapshot {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	clone := func(h histogram) histogram {
+		h.counts = append([]uint64(nil), h.counts...)
+		return h
+	}
+	return groupSnapshot{
+		started: gm.started, succeeded: gm.succeeded, failed: gm.failed, cancelled: gm.cancelled,
+		activeWorkers: gm.activeWorkers, duration: clone(gm.duration), queueWait: clone(gm.queueWait),
+	}
+}
+
+// observeWorker 记录`worker`的启动(`delta`为1)或退出(`delta`为-1)
+func (gm *groupMetrics) observeWorker(delta int64) {
+	if gm == nil {
+		return
+	}
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.activeWorkers += delta
+}
+
+// observeStart 记录任务开始执行，及其开始执行前的等待时长`wait`
+func (gm *groupMetrics) observeStart(wait time.Duration) {
+	if gm == nil {
+		return
+	}
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.started++
+	gm.queueWait.observe(gm.buckets, wait.Seconds())
+}
+
+// observeDone 记录任务的执行状态`err`，及其执行耗时`duration`
+func (gm *groupMetrics) observeDone(err error, duration time.Duration) {
+	if gm == nil {
+		return
+	}
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	if err != nil {
+		gm.failed++
+	} else {
+		gm.succeeded++
+	}
+	gm.duration.observe(gm.buckets, duration.Seconds())
+}
+
+// observeCancelled 记录因任务组被取消而未执行的任务数`n`
+func (gm *groupMetrics) observeCancelled(n int) {
+	if gm == nil || n <= 0 {
+		return
+	}
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.cancelled += uint64(n)
+}
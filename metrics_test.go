@@ -0,0 +1,68 @@
+package taskgroup_test
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+	"github.com/mlee-msl/taskgroup/taskgrouptest"
+)
+
+func TestMetrics(t *testing.T) {
+	var (
+		metrics = taskgroup.NewMetrics(0.5, 0.1)
+		clock   = taskgrouptest.NewFakeClock(time.Unix(0, 0))
+		errTask = errors.New("task err")
+	)
+	// 同名的任务组，指标将被合并
+	_, err := taskgroup.NewTaskGroup(taskgroup.WithMetrics(metrics, `orders"v1`), taskgrouptest.Deterministic(clock)).AddTask(
+		taskgrouptest.Succeeding(1, "ok", false),
+		taskgrouptest.Failing(2, errTask, false),
+	).Run()
+	if err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+	_, err = taskgroup.NewTaskGroup(taskgroup.WithMetrics(metrics, `orders"v1`), taskgrouptest.Deterministic(clock)).AddTask(
+		taskgrouptest.Failing(1, errTask, true),
+		taskgrouptest.Succeeding(2, "ok", false),
+		taskgrouptest.Succeeding(3, "ok", false),
+	).Run()
+	if !errors.Is(err, errTask) {
+		t.Fatalf("err=%+v", err)
+	}
+	taskgroup.NewTaskGroup(taskgroup.WithMetrics(metrics, "idle"))
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content type=%s", ct)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	for _, line := range []string{
+		"# TYPE taskgroup_tasks_started_total counter",
+		`taskgroup_tasks_started_total{group="idle"} 0`,
+		`taskgroup_tasks_started_total{group="orders\"v1"} 3`,
+		`taskgroup_tasks_succeeded_total{group="orders\"v1"} 1`,
+		`taskgroup_tasks_failed_total{group="orders\"v1"} 2`,
+		`taskgroup_tasks_cancelled_total{group="orders\"v1"} 2`,
+		"# TYPE taskgroup_task_duration_seconds histogram",
+		`taskgroup_task_duration_seconds_bucket{group="orders\"v1",le="0.1"} 3`,
+		`taskgroup_task_duration_seconds_bucket{group="orders\"v1",le="+Inf"} 3`,
+		`taskgroup_task_duration_seconds_count{group="orders\"v1"} 3`,
+		`taskgroup_task_queue_wait_seconds_sum{group="orders\"v1"} 0`,
+		"# TYPE taskgroup_active_workers gauge",
+		`taskgroup_active_workers{group="orders\"v1"} 0`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Errorf("missing %q in\n%s", line, body)
+		}
+	}
+	// 直方图的桶按上界升序输出
+	if strings.Index(string(body), `le="0.1"`) > strings.Index(string(body), `le="0.5"`) {
+		t.Errorf("buckets out of order\n%s", body)
+	}
+}
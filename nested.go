@@ -0,0 +1,54 @@
+package taskgroup
+
+import (
+	"context"
+	"sync"
+)
+
+// NewGroupTask 将任务组`child`包装为一个任务，父任务组运行时，子任务组将作为一个任务执行，
+// 其执行结果即为子任务组的执行结果`map[uint32]*TaskResult`(可通过[TaskResult.Children]获取)
+//
+// 子任务组的运行上下文继承自父任务组，父任务组执行失败或被取消时，子任务组也将被取消；
+// 子任务组执行失败时，是否导致父任务组执行失败，取决于该任务的`mustSuccess`
+//
+// 子任务组同一时刻仅运行一次，如，任务超时后重试时，将等待上一次运行结束，且不会对其进行推测执行
+func NewGroupTask(fNO uint32, child *TaskGroup, mustSuccess bool, opts ...TaskOption) *Task {
+	if child == nil {
+		return NewTask(fNO, nil, mustSuccess, opts...)
+	}
+
+	var mu sync.Mutex
+	t := NewContextTask(fNO, func(ctx context.Context) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return child.RunContext(ctx)
+	}, mustSuccess, opts...)
+	t.child = child
+	return t
+}
+
+// Children 获取子任务组的执行结果，任务不是由[NewGroupTask]创建时，返回`nil`
+func (tr *TaskResult) Children() map[uint32]*TaskResult {
+	if tr == nil {
+		return nil
+	}
+	children, _ := tr.Result().(map[uint32]*TaskResult)
+	return children
+}
+
+// childStats 收集任务`tasks`中所有子任务组的运行统计
+func childStats(tasks []*Task) map[uint32]*RunStats {
+	var stats map[uint32]*RunStats
+	for _, task := range tasks {
+		if task.child == nil {
+			continue
+		}
+		if childStats := task.child.Stats(); childStats != nil {
+			if stats == nil {
+				stats = make(map[uint32]*RunStats)
+			}
+			stats[task.fNO] = childStats
+		}
+	}
+	return stats
+}
@@ -0,0 +1,116 @@
+package taskgroup_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_nested(t *testing.T) {
+	const (
+		profileSection = 100
+		feedSection    = 200
+	)
+	testCases := []struct {
+		feedMustSuccess bool
+		isErr           bool
+	}{
+		{false, false},
+		{true, true},
+	}
+	for _, testCase := range testCases {
+		profile := taskgroup.NewTaskGroup().AddTask(
+			taskgroup.NewTask(1, task2ReturnSuccessWrapper(1, false), true),
+			taskgroup.NewTask(2, task4ReturnSuccessWrapper(2, false), true),
+		)
+		feed := taskgroup.NewTaskGroup().AddTask(
+			taskgroup.NewTask(1, task2ReturnSuccessWrapper(1, false), false),
+			taskgroup.NewTask(2, task3ReturnFailWrapper(2, false), true),
+		)
+
+		tg := taskgroup.NewTaskGroup().AddTask(
+			taskgroup.NewGroupTask(profileSection, profile, true),
+			taskgroup.NewGroupTask(feedSection, feed, testCase.feedMustSuccess),
+		)
+		results, err := tg.Run()
+		if (err != nil) != testCase.isErr {
+			t.Fatalf("feed must success=%v, err=%+v", testCase.feedMustSuccess, err)
+		}
+		if err != nil {
+			var taskErr *taskgroup.TaskError
+			if !errors.As(err, &taskErr) || taskErr.FNO != feedSection {
+				t.Errorf("err=%+v", err)
+			}
+			continue
+		}
+
+		if children := results[profileSection].Children(); len(children) != 2 || children[2].Error() != nil {
+			t.Errorf("profile results=%+v", children)
+		}
+		if results[feedSection].Error() == nil {
+			t.Errorf("feed err=%+v", results[feedSection].Error())
+		}
+		if stats := tg.Stats(); stats.Children[profileSection] == nil || stats.Children[profileSection].Succeeded != 2 {
+			t.Errorf("stats=%+v", stats)
+		}
+	}
+}
+
+func TestTaskGroupRun_nestedCancel(t *testing.T) {
+	errParent := errors.New("parent failed")
+	child := taskgroup.NewTaskGroup().AddTask(
+		taskgroup.NewContextTask(1, func(ctx context.Context) (interface{}, error) {
+			select {
+			case <-ctx.Done():
+				return nil, context.Cause(ctx)
+			case <-time.After(time.Second):
+				return nil, nil
+			}
+		}, true),
+	)
+	tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(2)).AddTask(
+		taskgroup.NewGroupTask(1, child, false),
+		taskgroup.NewTask(2, func() (interface{}, error) {
+			time.Sleep(5 * time.Millisecond)
+			return nil, errParent
+		}, true),
+	)
+
+	// 父任务组失败时，子任务组随之取消，无需等待其任务执行完成
+	start := time.Now()
+	if _, err := tg.Run(); !errors.Is(err, errParent) || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("elapsed=%v, err=%+v", time.Since(start), err)
+	}
+}
+
+func TestTaskGroupRun_nestedRetry(t *testing.T) {
+	var (
+		running atomic.Int32
+		overlap atomic.Bool
+	)
+	child := taskgroup.NewTaskGroup().AddTask(
+		taskgroup.NewTask(1, func() (interface{}, error) {
+			if running.Add(1) > 1 {
+				overlap.Store(true)
+			}
+			defer running.Add(-1)
+			time.Sleep(30 * time.Millisecond)
+			return nil, nil
+		}, false),
+	)
+	// 子任务组超时后重试时，等待上一次运行结束，而不会并发运行同一子任务组
+	_, err := taskgroup.NewTaskGroup().AddTask(
+		taskgroup.NewGroupTask(1, child, false, taskgroup.WithTimeout(10*time.Millisecond), taskgroup.WithRetries(2)),
+	).Run()
+	if err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if overlap.Load() {
+		t.Error("child group ran concurrently")
+	}
+}
@@ -0,0 +1,280 @@
+package taskgroup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StageFunc 流水线阶段的处理方法，`in`为上一阶段(或数据源)的输出
+type StageFunc func(ctx context.Context, in interface{}) (interface{}, error)
+
+// Stage 表示流水线中的一个处理阶段，阶段内的每个数据项将作为一个任务，在该阶段独立的任务组中执行
+type Stage struct {
+	name string    // 阶段名称
+	f    StageFunc // 阶段处理方法
+	opts []Option  // 阶段任务组的配置项
+}
+
+// NewStage 创建一个流水线阶段，`opts`复用了任务组的配置项(如，[WithWorkerNums])，未指定协程数时，默认为1
+//
+// 阶段的任务组以[ShedBlock]策略持续接收上游的数据项，待执行的数据项数上限与协程数一致，指定的[WithMaxQueuedTasks]将被忽略
+func NewStage(name string, f StageFunc, opts ...Option) *Stage {
+	return &Stage{name, f, opts}
+}
+
+// newGroup 创建阶段单次运行的任务组
+func (s *Stage) newGroup() *TaskGroup {
+	tg := NewTaskGroup(s.opts...)
+	WithMaxQueuedTasks(workerNumsOf(tg), ShedBlock)(tg)
+	return tg
+}
+
+// workerNumsOf 获取阶段的任务组`tg`的协程数
+func workerNumsOf(tg *TaskGroup) uint32 {
+	return If(tg.workerNums == 0 || tg.sequential || tg.replay != nil, uint32(1), tg.workerNums).(uint32)
+}
+
+// StageStats 表示流水线中某一阶段的运行统计
+type StageStats struct {
+	Name       string        // 阶段名称
+	WorkerNums uint32        // 阶段的协程数
+	Processed  uint64        // 处理成功的数据项数量
+	Failed     uint64        // 处理失败的数据项数量
+	Busy       time.Duration // 所有协程处理数据项的累计耗时
+}
+
+// stageCounter 阶段运行期间的统计计数
+type stageCounter struct {
+	workerNums              uint32
+	processed, failed, busy atomic.Uint64
+}
+
+// PipelineOption 表示流水线对象默认行为的修改
+type PipelineOption func(*Pipeline)
+
+// WithBufferSize 指定相邻阶段间缓冲区的容量`bufferSize`，当下游阶段处理不及时，缓冲区满后上游阶段将阻塞等待(背压)
+//
+// 未指定时，缓冲区容量默认与下游阶段的协程数一致
+func WithBufferSize(bufferSize uint32) PipelineOption {
+	return func(p *Pipeline) {
+		if p == nil {
+			return
+		}
+		p.bufferSize = bufferSize
+	}
+}
+
+// WithOrderedOutput 指定流水线的输出与输入保持一致的顺序，默认为无序输出(按处理完成的先后)
+func WithOrderedOutput() PipelineOption {
+	return func(p *Pipeline) {
+		if p == nil {
+			return
+		}
+		p.ordered = true
+	}
+}
+
+// Pipeline 表示由多个阶段串联而成的流水线，各阶段在各自的任务组中独立并发执行，阶段间通过有界缓冲区相连
+//
+// 任一阶段处理失败时，将立即取消所有阶段的执行，并返回该失败原因
+type Pipeline struct {
+	bufferSize uint32
+	ordered    bool
+	stages     []*Stage
+
+	mu    sync.Mutex
+	stats []StageStats // 最近一次运行的各阶段统计
+}
+
+// NewPipeline 创建一个流水线对象
+func NewPipeline(opts ...PipelineOption) *Pipeline {
+	p := new(Pipeline)
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+	return p
+}
+
+// AddStage 按顺序向流水线中追加若干阶段`stages`
+func (p *Pipeline) AddStage(stages ...*Stage) *Pipeline {
+	if p == nil {
+		return nil
+	}
+
+	for _, stage := range stages {
+		if stage == nil || stage.f == nil {
+			continue
+		}
+		p.stages = append(p.stages, stage)
+	}
+	return p
+}
+
+// pipelineItem 流水线中流转的数据项，`seq`为数据项在输入中的序号
+type pipelineItem struct {
+	seq int
+	v   interface{}
+}
+
+// Run 将`inputs`依次流经流水线的所有阶段，并返回最后一个阶段的输出
+//
+// 当返回`non-nil`错误时，则，返回的输出将不可信
+func (p *Pipeline) Run(ctx context.Context, inputs []interface{}) ([]interface{}, error) {
+	if p == nil || len(p.stages) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		groups   = make([]*TaskGroup, len(p.stages))
+		counters = make([]stageCounter, len(p.stages))
+	)
+	for i, stage := range p.stages {
+		groups[i] = stage.newGroup()
+		counters[i].workerNums = workerNumsOf(groups[i])
+	}
+	source := make(chan pipelineItem, p.bufferSizeOf(&counters[0]))
+	go func() {
+		defer close(source)
+		for seq, v := range inputs {
+			select {
+			case <-ctx.Done():
+				return
+			case source <- pipelineItem{seq, v}:
+			}
+		}
+	}()
+
+	var (
+		once sync.Once
+		out  <-chan pipelineItem = source
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			cancel(err)
+		})
+	}
+	for i, stage := range p.stages {
+		var next *stageCounter
+		if i+1 < len(p.stages) {
+			next = &counters[i+1]
+		}
+		out = p.runStage(ctx, fail, stage, groups[i], &counters[i], out, p.bufferSizeOf(next))
+	}
+
+	outputs := make([]interface{}, 0, len(inputs))
+	if p.ordered {
+		outputs = outputs[:len(inputs)]
+	}
+	for item := range out {
+		if p.ordered {
+			outputs[item.seq] = item.v
+			continue
+		}
+		outputs = append(outputs, item.v)
+	}
+
+	p.recordStats(counters)
+	return outputs, context.Cause(ctx)
+}
+
+// runStage 在任务组`tg`中执行阶段`stage`，来自`in`的数据项将作为任务持续加入任务组，返回该阶段的输出通道
+func (p *Pipeline) runStage(ctx context.Context, fail func(error), stage *Stage, tg *TaskGroup, counter *stageCounter,
+	in <-chan pipelineItem, bufferSize uint32) <-chan pipelineItem {
+	out := make(chan pipelineItem, bufferSize)
+	go func() {
+		defer close(out)
+		// 任务组的运行不随流水线取消，而是由各任务感知取消后尽快结束，以免添加任务时因任务组已结束而阻塞
+		results, err := tg.RunContext(context.WithoutCancel(ctx))
+		// 任务方法之外的失败(如，注入的故障、被看门狗放弃)同样视为阶段处理失败
+		errs := Results(results).Errors()
+		counter.failed.Add(uint64(len(errs)))
+		if err == nil && len(errs) > 0 {
+			err = errs[0]
+		}
+		if err != nil {
+			fail(fmt.Errorf("Pipeline: stage %q: %w", stage.name, err))
+		}
+	}()
+
+	go func() {
+		defer tg.CloseTasks()
+		for item := range in {
+			if ctx.Err() != nil { // 接收到`ctx`被取消的信号，即刻停止后续数据项的处理
+				return
+			}
+			tg.AddTask(NewContextTask(uint32(item.seq)+1, stageTask(ctx, fail, stage, counter, item, out), false))
+		}
+	}()
+	return out
+}
+
+// stageTask 阶段`stage`处理数据项`item`的任务方法，处理结果将发送至`out`
+func stageTask(ctx context.Context, fail func(error), stage *Stage, counter *stageCounter, item pipelineItem, out chan<- pipelineItem) ContextTaskFunc {
+	return func(taskCtx context.Context) (interface{}, error) {
+		if ctx.Err() != nil { // 流水线已被取消，不再处理数据项
+			return nil, nil
+		}
+		// 任务的上下文同时感知流水线的取消
+		taskCtx, cancel := context.WithCancelCause(taskCtx)
+		defer cancel(nil)
+		defer context.AfterFunc(ctx, func() { cancel(context.Cause(ctx)) })()
+
+		start := time.Now()
+		v, err := stage.f(taskCtx, item.v)
+		counter.busy.Add(uint64(time.Since(start)))
+		if err != nil {
+			fail(fmt.Errorf("Pipeline: stage %q: %w", stage.name, err))
+			return nil, err
+		}
+		counter.processed.Add(1)
+		select {
+		case <-ctx.Done():
+		case out <- pipelineItem{item.seq, v}:
+		}
+		return nil, nil
+	}
+}
+
+// bufferSizeOf 获取流向阶段的缓冲区容量，`counter`为阶段的统计计数，为`nil`时，表示流水线的最终输出
+func (p *Pipeline) bufferSizeOf(counter *stageCounter) uint32 {
+	if p.bufferSize > 0 || counter == nil {
+		return p.bufferSize
+	}
+	return counter.workerNums
+}
+
+func (p *Pipeline) recordStats(counters []stageCounter) {
+	stats := make([]StageStats, 0, len(p.stages))
+	for i, stage := range p.stages {
+		stats = append(stats, StageStats{
+			Name:       stage.name,
+			WorkerNums: counters[i].workerNums,
+			Processed:  counters[i].processed.Load(),
+			Failed:     counters[i].failed.Load(),
+			Busy:       time.Duration(counters[i].busy.Load()),
+		})
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats = stats
+}
+
+// Stats 获取最近一次运行时各阶段的统计
+func (p *Pipeline) Stats() []StageStats {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]StageStats(nil), p.stats...)
+}
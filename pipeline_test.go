@@ -0,0 +1,96 @@
+package taskgroup_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestPipelineRun(t *testing.T) {
+	double := taskgroup.NewStage("double", func(_ context.Context, in interface{}) (interface{}, error) {
+		simulateIO("double")
+		return in.(int) * 2, nil
+	}, taskgroup.WithWorkerNums(4))
+	incr := taskgroup.NewStage("incr", func(_ context.Context, in interface{}) (interface{}, error) {
+		return in.(int) + 1, nil
+	}, taskgroup.WithWorkerNums(2))
+
+	inputs := make([]interface{}, 0, 50)
+	expected := make([]interface{}, 0, 50)
+	for i := 0; i < 50; i++ {
+		inputs = append(inputs, i)
+		expected = append(expected, i*2+1)
+	}
+
+	testCases := []struct {
+		opts    []taskgroup.PipelineOption
+		ordered bool
+	}{
+		{nil, false},
+		{[]taskgroup.PipelineOption{taskgroup.WithBufferSize(1)}, false},
+		{[]taskgroup.PipelineOption{taskgroup.WithOrderedOutput()}, true},
+	}
+	for _, testCase := range testCases {
+		p := taskgroup.NewPipeline(testCase.opts...).AddStage(double, incr)
+		outputs, err := p.Run(context.Background(), inputs)
+		if err != nil {
+			t.Fatalf("err: %+v", err)
+		}
+		if !testCase.ordered {
+			sort.Slice(outputs, func(i, j int) bool { return outputs[i].(int) < outputs[j].(int) })
+		}
+		if !reflect.DeepEqual(outputs, expected) {
+			t.Errorf("outputs=%v, expected=%v", outputs, expected)
+		}
+		stats := p.Stats()
+		if len(stats) != 2 || stats[0].Name != "double" || stats[0].WorkerNums != 4 || stats[1].Processed != 50 {
+			t.Errorf("stats=%+v", stats)
+		}
+	}
+}
+
+func TestPipelineRun_fail(t *testing.T) {
+	errStage := errors.New("transform err")
+	p := taskgroup.NewPipeline().AddStage(
+		taskgroup.NewStage("fetch", func(_ context.Context, in interface{}) (interface{}, error) {
+			return in, nil
+		}),
+		taskgroup.NewStage("transform", func(_ context.Context, in interface{}) (interface{}, error) {
+			if in.(int) == 3 {
+				return nil, errStage
+			}
+			return in, nil
+		}, taskgroup.WithWorkerNums(2)),
+	)
+
+	_, err := p.Run(context.Background(), []interface{}{1, 2, 3, 4, 5})
+	if !errors.Is(err, errStage) {
+		t.Errorf("err=%+v, expected=%+v", err, errStage)
+	}
+	if stats := p.Stats(); stats[1].Failed != 1 {
+		t.Errorf("stats=%+v", stats)
+	}
+}
+
+func TestPipelineRun_groupOptions(t *testing.T) {
+	echo := func(_ context.Context, in interface{}) (interface{}, error) { return in, nil }
+	// 阶段的数据项作为任务执行，任务组的配置项同样生效(数据项的任务编号为其在输入中的序号加1)
+	p := taskgroup.NewPipeline().AddStage(
+		taskgroup.NewStage("fetch", echo, taskgroup.WithSequential(), taskgroup.WithWorkerNums(4)),
+		taskgroup.NewStage("transform", echo, nil, taskgroup.WithWorkerNums(2), taskgroup.WithFaultInjection(1, taskgroup.Fault{
+			Kind: taskgroup.FaultError, Stage: taskgroup.FaultAfterCall, FNOs: []uint32{3},
+		})),
+	)
+
+	_, err := p.Run(context.Background(), []interface{}{1, 2, 3, 4, 5})
+	if !errors.Is(err, taskgroup.ErrInjectedFault) {
+		t.Errorf("err=%+v, expected=%+v", err, taskgroup.ErrInjectedFault)
+	}
+	if stats := p.Stats(); stats[0].WorkerNums != 1 || stats[1].WorkerNums != 2 || stats[1].Failed != 1 {
+		t.Errorf("stats=%+v", stats)
+	}
+}
@@ -0,0 +1,259 @@
+package taskgroup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// EventType 表示运行事件的类型
+type EventType string
+
+const (
+	EventTaskQueued     EventType = "task_queued"     // 任务入队
+	EventTaskStarted    EventType = "task_started"    // 任务开始执行
+	EventTaskFinished   EventType = "task_finished"   // 任务执行结束
+	EventTaskCancelled  EventType = "task_cancelled"  // 任务因任务组被取消而未执行
+	EventGroupCancelled EventType = "group_cancelled" // 任务组被取消(执行失败或`ctx`被取消)
+)
+
+// Event 表示任务组运行过程中的一个事件
+type Event struct {
+	Type   EventType       `json:"type"`
+	Time   time.Time       `json:"time"`
+	FNO    uint32          `json:"fno,omitempty"`
+	Worker int             `json:"worker,omitempty"` // 执行任务的协程编号(从1开始)
+	Result json.RawMessage `json:"result,omitempty"` // `json`编码后的任务执行结果，仅[EventTaskFinished]，回放时按任务的结果类型解码(见[WithResultType])
+	Err    string          `json:"err,omitempty"`    // 任务执行失败或任务组被取消的原因
+	Failed bool            `json:"failed,omitempty"` // 任务是否执行失败，仅[EventTaskFinished]
+}
+
+// Recorder 表示运行事件的记录器，需是并发安全的
+type Recorder interface {
+	Record(event Event)
+}
+
+// WithRecorder 指定任务组运行事件的记录器`recorder`
+func WithRecorder(recorder Recorder) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.recorder = recorder
+	}
+}
+
+// WithReplay 按照记录的运行事件`events`回放任务组的运行：任务将按记录的开始顺序在单个协程上依次"执行"，
+// 但不会调用任务方法，而是直接使用记录的执行结果，记录中未开始执行的任务将不会执行
+//
+// 可用以在单元测试中稳定复现线上出现的问题
+func WithReplay(events []Event) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.replay = newReplayer(events)
+	}
+}
+
+// EventLog 基于内存的运行事件记录器
+type EventLog struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// Record 记录事件
+func (l *EventLog) Record(event Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+// Events 获取所有已记录的事件
+func (l *EventLog) Events() []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Event(nil), l.events...)
+}
+
+// JSONRecorder 将运行事件以`json lines`的形式写入`io.Writer`的记录器
+type JSONRecorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewJSONRecorder 创建一个写入`w`的记录器
+func NewJSONRecorder(w io.Writer) *JSONRecorder {
+	return &JSONRecorder{enc: json.NewEncoder(w)}
+}
+
+// Record 记录事件，写入失败后将不再写入后续的事件
+func (r *JSONRecorder) Record(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.enc.Encode(event)
+	}
+}
+
+// Err 获取写入事件时的首个错误
+func (r *JSONRecorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// ReadEvents 读取由[JSONRecorder]写入的运行事件
+func ReadEvents(r io.Reader) ([]Event, error) {
+	var (
+		events []Event
+		dec    = json.NewDecoder(r)
+	)
+	for {
+		var event Event
+		if err := dec.Decode(&event); err == io.EOF {
+			return events, nil
+		} else if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+}
+
+// eventRecorder 任务组单次运行期间的事件记录
+type eventRecorder struct {
+	recorder Recorder
+	clock    Clock
+
+	mu              sync.Mutex
+	startedFNOs     map[uint32]struct{}
+	groupCancelOnce sync.Once
+}
+
+func (tg *TaskGroup) newEventRecorder() *eventRecorder {
+	if tg.recorder == nil {
+		return nil
+	}
+	return &eventRecorder{recorder: tg.recorder, clock: tg.runClock(), startedFNOs: make(map[uint32]struct{})}
+}
+
+func (er *eventRecorder) queued(tasks []*Task) {
+	if er == nil {
+		return
+	}
+	for _, task := range tasks {
+		er.recorder.Record(Event{Type: EventTaskQueued, Time: er.clock.Now(), FNO: task.fNO})
+	}
+}
+
+func (er *eventRecorder) started(task *Task, workerID int) {
+	if er == nil {
+		return
+	}
+	er.mu.Lock()
+	er.startedFNOs[task.fNO] = struct{}{}
+	er.mu.Unlock()
+	er.recorder.Record(Event{Type: EventTaskStarted, Time: er.clock.Now(), FNO: task.fNO, Worker: workerID})
+}
+
+func (er *eventRecorder) finished(result *TaskResult, workerID int) {
+	if er == nil {
+		return
+	}
+	event := Event{Type: EventTaskFinished, Time: er.clock.Now(), FNO: result.fNO, Worker: workerID}
+	if result.result != nil {
+		// 无法编码的任务结果不会被记录，回放时为`nil`
+		event.Result, _ = json.Marshal(result.result)
+	}
+	if result.err != nil {
+		event.Err, event.Failed = result.err.Error(), true
+	}
+	er.recorder.Record(event)
+}
+
+// groupCancelled 记录任务组被取消的原因`cause`，仅记录首次
+func (er *eventRecorder) groupCancelled(cause error) {
+	if er == nil {
+		return
+	}
+	er.groupCancelOnce.Do(func() {
+		er.recorder.Record(Event{Type: EventGroupCancelled, Time: er.clock.Now(), Err: cause.Error()})
+	})
+}
+
+// cancelled 记录任务`tasks`中所有未开始执行的任务
+func (er *eventRecorder) cancelled(tasks []*Task) {
+	if er == nil {
+		return
+	}
+	er.mu.Lock()
+	defer er.mu.Unlock()
+	for _, task := range tasks {
+		if _, has := er.startedFNOs[task.fNO]; !has {
+			er.recorder.Record(Event{Type: EventTaskCancelled, Time: er.clock.Now(), FNO: task.fNO})
+		}
+	}
+}
+
+// errNotRecorded 回放时，任务开始执行但没有记录执行结果(如，记录时进程崩溃)
+var errNotRecorded = errors.New("taskgroup: task result not recorded")
+
+// replayer 按照记录的运行事件回放任务组的运行
+type replayer struct {
+	order    []uint32         // 任务开始执行的顺序
+	finished map[uint32]Event // 任务执行结束的事件
+}
+
+func newReplayer(events []Event) *replayer {
+	rp := &replayer{finished: make(map[uint32]Event)}
+	for _, event := range events {
+		switch event.Type {
+		case EventTaskStarted:
+			rp.order = append(rp.order, event.FNO)
+		case EventTaskFinished:
+			rp.finished[event.FNO] = event
+		}
+	}
+	return rp
+}
+
+// arrange 按照记录的开始顺序重排任务`tasks`，记录中未开始执行的任务将被移除
+func (rp *replayer) arrange(tasks []*Task) []*Task {
+	taskMap := make(map[uint32]*Task, len(tasks))
+	for _, task := range tasks {
+		taskMap[task.fNO] = task
+	}
+	arranged := make([]*Task, 0, len(rp.order))
+	for _, fNO := range rp.order {
+		if task, has := taskMap[fNO]; has {
+			arranged = append(arranged, task)
+		}
+	}
+	return arranged
+}
+
+// result 获取任务`task`记录的执行结果
+func (rp *replayer) result(task *Task) *TaskResult {
+	event, has := rp.finished[task.fNO]
+	if !has {
+		return newTaskResult(task, nil, errNotRecorded)
+	}
+	var err error
+	if event.Failed {
+		err = errors.New(event.Err)
+	}
+	result := newTaskResult(task, nil, err)
+	result.attempt = 1
+	if len(event.Result) == 0 {
+		return result
+	}
+	// 记录的任务结果按任务的结果类型解码，以与记录时的结果类型一致
+	if decodeErr := result.decodeResult(&encodedResult{data: event.Result, codec: JSONCodec{}}, task.resultType); decodeErr != nil && err == nil {
+		result.err = fmt.Errorf("replay: decode result: %w", decodeErr)
+	}
+	return result
+}
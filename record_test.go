@@ -0,0 +1,85 @@
+package taskgroup_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_record(t *testing.T) {
+	errTask := errors.New("task 3 err")
+	newTasks := func(f func(fNO uint32) (interface{}, error)) []*taskgroup.Task {
+		var tasks []*taskgroup.Task
+		for fNO := uint32(1); fNO <= 4; fNO++ {
+			fNO := fNO
+			tasks = append(tasks, taskgroup.NewTask(fNO, func() (interface{}, error) { return f(fNO) }, fNO <= 3, taskgroup.WithResultType(thumbnail{})))
+		}
+		return tasks
+	}
+
+	var (
+		buf      bytes.Buffer
+		log      taskgroup.EventLog
+		recorder = taskgroup.NewJSONRecorder(&buf)
+	)
+	tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(1), taskgroup.WithRecorder(multiRecorder{&log, recorder}))
+	_, err := tg.AddTask(newTasks(func(fNO uint32) (interface{}, error) {
+		if fNO == 3 {
+			return nil, errTask
+		}
+		return thumbnail{Size: int(fNO), URL: "ok"}, nil
+	})...).Run()
+	if !errors.Is(err, errTask) || recorder.Err() != nil {
+		t.Fatalf("err=%+v, recorder err=%+v", err, recorder.Err())
+	}
+
+	counts := make(map[taskgroup.EventType]int)
+	for _, event := range log.Events() {
+		counts[event.Type]++
+		if event.Type == taskgroup.EventTaskStarted && event.Worker != 1 {
+			t.Errorf("event=%+v", event)
+		}
+	}
+	if counts[taskgroup.EventTaskQueued] != 4 || counts[taskgroup.EventGroupCancelled] != 1 ||
+		counts[taskgroup.EventTaskStarted] != counts[taskgroup.EventTaskFinished] ||
+		counts[taskgroup.EventTaskStarted]+counts[taskgroup.EventTaskCancelled] != 4 {
+		t.Errorf("counts=%+v", counts)
+	}
+
+	// 从`json`中读取事件后回放，任务方法不会被调用，结果(含类型)与记录时一致
+	events, err := taskgroup.ReadEvents(&buf)
+	if err != nil || len(events) != len(log.Events()) {
+		t.Fatalf("events=%d, err=%+v", len(events), err)
+	}
+	replayed, err := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(4), taskgroup.WithReplay(events)).AddTask(
+		newTasks(func(fNO uint32) (interface{}, error) {
+			t.Errorf("task %d called during replay", fNO)
+			return nil, nil
+		})...).Run()
+	var taskErr *taskgroup.TaskError
+	if !errors.As(err, &taskErr) || taskErr.FNO != 3 || taskErr.Err.Error() != errTask.Error() {
+		t.Errorf("replay err=%+v", err)
+	}
+	if len(replayed) < 2 {
+		t.Fatalf("replayed=%+v", replayed)
+	}
+	for fNO, result := range replayed {
+		if result.Error() != nil {
+			continue
+		}
+		if v, err := taskgroup.ResultAs[thumbnail](replayed, fNO); err != nil || v != (thumbnail{Size: int(fNO), URL: "ok"}) {
+			t.Errorf("replay fno=%d, result=%+v, err=%+v", fNO, result.Result(), err)
+		}
+	}
+}
+
+// multiRecorder 将事件同时记录到多个记录器
+type multiRecorder []taskgroup.Recorder
+
+func (m multiRecorder) Record(event taskgroup.Event) {
+	for _, recorder := range m {
+		recorder.Record(event)
+	}
+}
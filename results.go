@@ -0,0 +1,158 @@
+package taskgroup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+var (
+	// ErrTaskNotFound 执行结果中不存在该任务(任务未添加、未执行或因任务组执行失败而未被收集)
+	ErrTaskNotFound = errors.New("taskgroup: task not found in results")
+	// ErrNilResult 任务执行成功，但返回的执行结果为`nil`
+	ErrNilResult = errors.New("taskgroup: task returned nil result")
+)
+
+// Results 表示任务组的执行结果集合，以任务编号(标识)为键
+type Results map[uint32]*TaskResult
+
+// RunResults 同[TaskGroup.RunContext]，但以[Results]的形式返回执行结果
+func (tg *TaskGroup) RunResults(ctx context.Context) (Results, error) {
+	results, err := tg.RunContext(ctx)
+	return Results(results), err
+}
+
+// Get 获取任务`fNO`的执行结果，不存在时，返回[ErrTaskNotFound]
+func (rs Results) Get(fNO uint32) (*TaskResult, error) {
+	result, has := rs[fNO]
+	if !has || result == nil {
+		return nil, fmt.Errorf("task %d: %w", fNO, ErrTaskNotFound)
+	}
+	return result, nil
+}
+
+// ResultAs 获取任务`fNO`的执行结果，并转换为类型`T`
+//
+// 任务不存在时，返回[ErrTaskNotFound]；任务执行失败时，返回任务的错误信息；执行结果为`nil`时，返回[ErrNilResult]；
+// 执行结果不是类型`T`时，返回类型不匹配的错误，但来自检查点或磁盘缓存的执行结果将按需解码为类型`T`；已被清理的执行结果视为`nil`
+func ResultAs[T any](rs Results, fNO uint32) (T, error) {
+	var zero T
+	result, err := rs.Get(fNO)
+	if err != nil {
+		return zero, err
+	}
+	if result.err != nil {
+		return zero, result.taskError()
+	}
+	value := result.Result()
+	if value == nil {
+		return zero, fmt.Errorf("task %d: %w", fNO, ErrNilResult)
+	}
+	v, ok := value.(T)
+	if !ok && result.encoded != nil { // 来自检查点、磁盘缓存或回放的任务结果，按需解码为`T`
+		var decoded interface{}
+		if decoded, err = result.encoded.decode(reflect.TypeOf(&zero).Elem()); err == nil {
+			v, ok = decoded.(T)
+		}
+	}
+	if !ok {
+		return zero, fmt.Errorf("task %d: result type is %T, not %T", fNO, value, zero)
+	}
+	return v, nil
+}
+
+// FNOs 获取所有任务的编号(标识)，按升序排列
+func (rs Results) FNOs() []uint32 {
+	fNOs := make([]uint32, 0, len(rs))
+	for fNO := range rs {
+		fNOs = append(fNOs, fNO)
+	}
+	sort.Slice(fNOs, func(i, j int) bool { return fNOs[i] < fNOs[j] })
+	return fNOs
+}
+
+// Each 按任务编号(标识)升序依次遍历执行结果，`f`返回`false`时，停止遍历
+func (rs Results) Each(f func(result *TaskResult) bool) {
+	for _, fNO := range rs.FNOs() {
+		if !f(rs[fNO]) {
+			return
+		}
+	}
+}
+
+// Succeeded 获取所有执行成功的任务结果，按任务编号(标识)升序排列
+func (rs Results) Succeeded() []*TaskResult {
+	return rs.filter(func(result *TaskResult) bool { return result.Error() == nil })
+}
+
+// Failed 获取所有执行失败(含被丢弃)的任务结果，按任务编号(标识)升序排列
+func (rs Results) Failed() []*TaskResult {
+	return rs.filter(func(result *TaskResult) bool { return result.Error() != nil })
+}
+
+// Errors 获取所有执行失败的任务的错误信息(以[*TaskError]包装)，按任务编号(标识)升序排列
+func (rs Results) Errors() []error {
+	failed := rs.Failed()
+	errs := make([]error, 0, len(failed))
+	for _, result := range failed {
+		errs = append(errs, result.taskError())
+	}
+	return errs
+}
+
+// Select 筛选出标签与选择器`selector`匹配的任务结果，见[WithLabelSelector]
+func (rs Results) Select(selector map[string]string) Results {
+	selected := make(Results, len(rs))
+	for fNO, result := range rs {
+		if matchLabels(result.Labels(), selector) {
+			selected[fNO] = result
+		}
+	}
+	return selected
+}
+
+func (rs Results) filter(match func(result *TaskResult) bool) []*TaskResult {
+	var matched []*TaskResult
+	rs.Each(func(result *TaskResult) bool {
+		if match(result) {
+			matched = append(matched, result)
+		}
+		return true
+	})
+	return matched
+}
+
+// resultJSON 任务结果的`json`表示
+type resultJSON struct {
+	FNO    uint32            `json:"fno"`
+	Name   string            `json:"name,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Result interface{}       `json:"result"`
+	Error  string            `json:"error,omitempty"`
+	Shed   bool              `json:"shed,omitempty"`
+}
+
+// MarshalJSON 将执行结果编码为按任务编号(标识)升序排列的`json`数组
+func (rs Results) MarshalJSON() ([]byte, error) {
+	results := make([]resultJSON, 0, len(rs))
+	rs.Each(func(result *TaskResult) bool {
+		r := resultJSON{FNO: result.FNO(), Name: result.Name(), Labels: result.Labels(), Result: result.Result(), Shed: result.Shed()}
+		if err := result.Error(); err != nil {
+			r.Error = err.Error()
+		}
+		results = append(results, r)
+		return true
+	})
+	return json.Marshal(results)
+}
+
+// taskError 获取任务执行失败的错误信息，并以[*TaskError]包装
+func (tr *TaskResult) taskError() *TaskError {
+	if taskErr, ok := tr.err.(*TaskError); ok {
+		return taskErr
+	}
+	return &TaskError{FNO: tr.fNO, Name: tr.name, Err: tr.err}
+}
@@ -0,0 +1,51 @@
+package taskgroup_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestResults(t *testing.T) {
+	tasks := []*taskgroup.Task{
+		taskgroup.NewTask(3, task3ReturnFailWrapper(3, false), false),
+		taskgroup.NewTask(1, func() (interface{}, error) { return "mlee", nil }, true),
+		taskgroup.NewTask(2, func() (interface{}, error) { return nil, nil }, true),
+	}
+	results, err := taskgroup.NewTaskGroup().AddTask(tasks...).RunResults(context.Background())
+	if err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+
+	if v, err := taskgroup.ResultAs[string](results, 1); err != nil || v != "mlee" {
+		t.Errorf("v=%v, err=%+v", v, err)
+	}
+	if _, err := taskgroup.ResultAs[int](results, 1); err == nil {
+		t.Errorf("expected type mismatch err")
+	}
+	if _, err := taskgroup.ResultAs[string](results, 2); !errors.Is(err, taskgroup.ErrNilResult) {
+		t.Errorf("err=%+v, expected=%+v", err, taskgroup.ErrNilResult)
+	}
+	if _, err := taskgroup.ResultAs[string](results, 4); !errors.Is(err, taskgroup.ErrTaskNotFound) {
+		t.Errorf("err=%+v, expected=%+v", err, taskgroup.ErrTaskNotFound)
+	}
+	var taskErr *taskgroup.TaskError
+	if _, err := taskgroup.ResultAs[string](results, 3); !errors.As(err, &taskErr) || taskErr.FNO != 3 {
+		t.Errorf("err=%+v", err)
+	}
+
+	if succeeded := results.Succeeded(); len(succeeded) != 2 || succeeded[0].FNO() != 1 || succeeded[1].FNO() != 2 {
+		t.Errorf("succeeded=%+v", succeeded)
+	}
+	if failed, errs := results.Failed(), results.Errors(); len(failed) != 1 || failed[0].FNO() != 3 || len(errs) != 1 {
+		t.Errorf("failed=%+v, errs=%+v", failed, errs)
+	}
+
+	data, err := json.Marshal(results)
+	if expected := `[{"fno":1,"result":"mlee"},{"fno":2,"result":null},{"fno":3,"result":"TASK3: The data is 928","error":"task 3: fno: 3, TASK3 err"}]`; err != nil || string(data) != expected {
+		t.Errorf("json=%s, expected=%s, err=%+v", data, expected, err)
+	}
+}
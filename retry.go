@@ -0,0 +1,30 @@
+package taskgroup
+
+import "context"
+
+// WithRetries 指定任务执行失败后的最大重试次数`retries`，任务组被取消后不再重试，
+// 执行结果的[TaskResult.Attempt]将包含重试的次数
+func WithRetries(retries uint32) TaskOption {
+	return func(t *Task) {
+		if t == nil {
+			return
+		}
+		t.retries = retries
+	}
+}
+
+// attemptKey 运行上下文中任务当前执行次数的键
+type attemptKey struct{}
+
+// withAttempt 在运行上下文`ctx`中记录任务当前是第`attempt`次执行(含重试)
+func withAttempt(ctx context.Context, attempt uint32) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// attemptOf 获取运行上下文`ctx`中记录的任务当前的执行次数，未记录时，为1
+func attemptOf(ctx context.Context) uint32 {
+	if attempt, ok := ctx.Value(attemptKey{}).(uint32); ok {
+		return attempt
+	}
+	return 1
+}
@@ -0,0 +1,46 @@
+package taskgroup_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_retries(t *testing.T) {
+	var calls int32
+	errTask := errors.New("task err")
+	results, err := taskgroup.NewTaskGroup().AddTask(
+		taskgroup.NewTask(1, func() (interface{}, error) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				return nil, errTask
+			}
+			return "ok", nil
+		}, true, taskgroup.WithRetries(3)),
+		taskgroup.NewTask(2, func() (interface{}, error) { return nil, errTask }, false, taskgroup.WithRetries(1)),
+	).Run()
+	if err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+	if result := results[1]; result.Result() != "ok" || result.Attempt() != 3 {
+		t.Errorf("result=%+v, attempt=%d", result.Result(), result.Attempt())
+	}
+	if result := results[2]; !errors.Is(result.Error(), errTask) || result.Attempt() != 2 {
+		t.Errorf("err=%+v, attempt=%d", result.Error(), result.Attempt())
+	}
+
+	// 每次重试均单独计算超时
+	release := make(chan struct{})
+	defer close(release)
+	var timedOut int32
+	results, err = taskgroup.NewTaskGroup().AddTask(taskgroup.NewTask(1, func() (interface{}, error) {
+		atomic.AddInt32(&timedOut, 1)
+		<-release
+		return "late", nil
+	}, false, taskgroup.WithTimeout(10*time.Millisecond), taskgroup.WithRetries(1))).Run()
+	if err != nil || !errors.Is(results[1].Error(), taskgroup.ErrTaskTimeout) || results[1].Attempt() != 2 || atomic.LoadInt32(&timedOut) != 2 {
+		t.Errorf("err=%+v, task err=%+v, attempt=%d", err, results[1].Error(), results[1].Attempt())
+	}
+}
@@ -0,0 +1,142 @@
+package taskgroup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 表示任务组的调度计划
+type Schedule interface {
+	// Next 获取`t`之后(不含`t`)的下一次调度时间
+	Next(t time.Time) time.Time
+}
+
+// everySchedule 固定间隔的调度计划
+type everySchedule time.Duration
+
+func (e everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// Every 创建一个以固定间隔`interval`进行调度的计划，间隔不足1秒时，按1秒处理
+func Every(interval time.Duration) Schedule {
+	return everySchedule(If(interval < time.Second, time.Second, interval).(time.Duration))
+}
+
+// cronSchedule 基于`cron`表达式的调度计划，各字段以位集合表示其允许的取值
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	domRestricted, dowRestricted bool // 日期与星期均被限定时，两者满足其一即可(同标准`cron`)
+}
+
+// cronField `cron`表达式中单个字段的取值范围
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [...]cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// ParseCron 解析标准的5字段`cron`表达式(分 时 日 月 周)，如，`*/5 9-18 * * 1-5`
+//
+// 各字段支持`*`、单值、范围`a-b`、步长`/n`及以`,`分隔的列表，调度时间以传入[Schedule.Next]的时间所在时区计算
+func ParseCron(expr string) (Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("ParseCron: expected %d fields, got %d in %q", len(cronFields), len(fields), expr)
+	}
+
+	var bits [len(cronFields)]uint64
+	for i, field := range fields {
+		var err error
+		if bits[i], err = parseCronField(field, cronFields[i]); err != nil {
+			return nil, fmt.Errorf("ParseCron: %s field %q: %w", cronFields[i].name, field, err)
+		}
+	}
+	return &cronSchedule{
+		minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4],
+		domRestricted: fields[2] != "*", dowRestricted: fields[4] != "*",
+	}, nil
+}
+
+func parseCronField(field string, bound cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part[i+1:])
+			}
+			rangePart, step = part[:i], n
+		}
+
+		lo, hi := bound.min, bound.max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid value %q", bounds[0])
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid value %q", bounds[1])
+				}
+			} else if step > 1 { // 形如`a/n`，表示从`a`开始至最大值
+				hi = bound.max
+			}
+		}
+		if lo < bound.min || hi > bound.max || lo > hi {
+			return 0, fmt.Errorf("range %d-%d out of bounds [%d, %d]", lo, hi, bound.min, bound.max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next 逐级(月、日、时、分)查找满足表达式的下一个时间点，5年内无满足的时间点时，返回零值时间
+func (c *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *cronSchedule) matchDay(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domRestricted && c.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
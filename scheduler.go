@@ -0,0 +1,163 @@
+package taskgroup
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// OverlapPolicy 表示调度时间到达，而上一次运行还未结束时的处理策略
+type OverlapPolicy uint8
+
+const (
+	// OverlapSkip 跳过本次运行
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue 待上一次运行结束后立即运行，排队中的运行至多保留一次
+	OverlapQueue
+	// OverlapCancelPrevious 取消上一次运行(停止其还未启动的任务)，并立即开始本次运行
+	OverlapCancelPrevious
+)
+
+// ErrRunSuperseded 在[OverlapCancelPrevious]策略下，上一次运行被取消的原因
+var ErrRunSuperseded = errors.New("Scheduler: run superseded by the next scheduled run")
+
+// RunCallback 每次运行结束后的回调，`results`与`err`即为任务组的运行结果
+type RunCallback func(startedAt time.Time, results map[uint32]*TaskResult, err error)
+
+// SchedulerOption 表示调度器对象默认行为的修改
+type SchedulerOption func(*Scheduler)
+
+// WithOverlapPolicy 指定调度器的运行重叠策略`policy`，默认为[OverlapSkip]
+func WithOverlapPolicy(policy OverlapPolicy) SchedulerOption {
+	return func(s *Scheduler) {
+		if s == nil {
+			return
+		}
+		s.policy = policy
+	}
+}
+
+// WithJitter 指定每次调度时间的随机延迟上限`jitter`，避免多个实例同时运行
+func WithJitter(jitter time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		if s == nil {
+			return
+		}
+		s.jitter = jitter
+	}
+}
+
+// WithClock 指定调度器使用的时钟`clock`，默认为[SystemClock]
+func WithClock(clock Clock) SchedulerOption {
+	return func(s *Scheduler) {
+		if s == nil || clock == nil {
+			return
+		}
+		s.clock = clock
+	}
+}
+
+// Scheduler 表示按照调度计划重复运行任务组的调度器
+type Scheduler struct {
+	schedule Schedule
+	newGroup func() *TaskGroup // 任务组模板，每次运行都将创建一个新的任务组
+	callback RunCallback
+
+	policy OverlapPolicy
+	jitter time.Duration
+	clock  Clock
+
+	mu         sync.Mutex
+	wg         sync.WaitGroup
+	running    int                     // 运行中的任务组数量
+	queued     bool                    // 是否有排队中的运行
+	cancelLast context.CancelCauseFunc // 取消最近一次的运行
+}
+
+// NewScheduler 创建一个调度器，按照`schedule`重复运行由`newGroup`创建的任务组，每次运行结束后回调`callback`
+func NewScheduler(schedule Schedule, newGroup func() *TaskGroup, callback RunCallback, opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{schedule: schedule, newGroup: newGroup, callback: callback, clock: SystemClock}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+	return s
+}
+
+// Run 启动调度器并阻塞，直至`ctx`被取消
+//
+// 返回前将取消并等待所有运行中的任务组结束，返回值为`ctx`被取消的原因
+func (s *Scheduler) Run(ctx context.Context) error {
+	if s == nil || s.schedule == nil || s.newGroup == nil {
+		return nil
+	}
+
+	defer s.wg.Wait()
+	next := s.schedule.Next(s.clock.Now())
+	for !next.IsZero() {
+		wait := next.Sub(s.clock.Now())
+		if s.jitter > 0 {
+			wait += time.Duration(rand.Int63n(int64(s.jitter)))
+		}
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-s.clock.After(wait):
+		}
+
+		s.fire(ctx)
+		// 当运行落后于调度计划时，跳过已错过的调度时间
+		if next = s.schedule.Next(next); !next.IsZero() && next.Before(s.clock.Now()) {
+			next = s.schedule.Next(s.clock.Now())
+		}
+	}
+	<-ctx.Done()
+	return context.Cause(ctx)
+}
+
+// fire 调度时间到达，按照重叠策略决定是否运行
+func (s *Scheduler) fire(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running > 0 {
+		switch s.policy {
+		case OverlapQueue:
+			s.queued = true
+			return
+		case OverlapCancelPrevious:
+			s.cancelLast(ErrRunSuperseded)
+		default:
+			return
+		}
+	}
+	s.start(ctx)
+}
+
+// start 启动一次运行，调用方需持有锁
+func (s *Scheduler) start(ctx context.Context) {
+	runCtx, cancel := context.WithCancelCause(ctx)
+	s.running++
+	s.cancelLast = cancel
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		startedAt := s.clock.Now()
+		results, err := s.newGroup().RunContext(runCtx)
+		cancel(nil)
+		if s.callback != nil {
+			s.callback(startedAt, results, err)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.running--
+		if s.queued && ctx.Err() == nil {
+			s.queued = false
+			s.start(ctx)
+		}
+	}()
+}
@@ -0,0 +1,92 @@
+package taskgroup_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+	"github.com/mlee-msl/taskgroup/taskgrouptest"
+)
+
+func TestScheduler(t *testing.T) {
+	testCases := []struct {
+		policy   taskgroup.OverlapPolicy
+		expected int32 // 推进3次时钟后，完成的运行次数
+	}{
+		{taskgroup.OverlapSkip, 1},
+		{taskgroup.OverlapQueue, 2},
+		{taskgroup.OverlapCancelPrevious, 3},
+	}
+	for _, testCase := range testCases {
+		var (
+			clock   = taskgrouptest.NewFakeClock(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+			release = make(chan struct{})
+			runs    atomic.Int32
+			done    = make(chan error, 3)
+		)
+		newGroup := func() *taskgroup.TaskGroup {
+			return taskgroup.NewTaskGroup().AddTask(taskgroup.NewTask(1, func() (interface{}, error) {
+				<-release
+				return nil, nil
+			}, true))
+		}
+		s := taskgroup.NewScheduler(taskgroup.Every(time.Minute), newGroup, func(_ time.Time, _ map[uint32]*taskgroup.TaskResult, err error) {
+			runs.Add(1)
+			done <- err
+		}, taskgroup.WithClock(clock), taskgroup.WithOverlapPolicy(testCase.policy))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan error)
+		go func() {
+			stopped <- s.Run(ctx)
+		}()
+		for i := 0; i < 3; i++ {
+			clock.BlockUntil(1)
+			clock.Advance(time.Minute)
+		}
+		clock.BlockUntil(1) // 确保第3次调度已处理
+		close(release)
+		if testCase.policy == taskgroup.OverlapQueue { // 排队的运行结束后，方可停止调度器
+			<-done
+			<-done
+		}
+		cancel()
+		if err := <-stopped; !errors.Is(err, context.Canceled) {
+			t.Errorf("policy=%d, err=%+v", testCase.policy, err)
+		}
+		if got := runs.Load(); got != testCase.expected {
+			t.Errorf("policy=%d, runs=%d, expected=%d", testCase.policy, got, testCase.expected)
+		}
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		from     time.Time
+		expected time.Time
+		isErr    bool
+	}{
+		{"*/15 9-17 * * 1-5", time.Date(2024, 6, 7, 17, 50, 0, 0, time.UTC), time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC), false},
+		{"0 0 1 * *", time.Date(2024, 2, 10, 8, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"30 2 * * *", time.Date(2024, 6, 1, 2, 30, 0, 0, time.UTC), time.Date(2024, 6, 2, 2, 30, 0, 0, time.UTC), false},
+		{"* * *", time.Time{}, time.Time{}, true},
+		{"61 * * * *", time.Time{}, time.Time{}, true},
+	}
+	for _, testCase := range testCases {
+		schedule, err := taskgroup.ParseCron(testCase.expr)
+		if (err != nil) != testCase.isErr {
+			t.Errorf("expr=%q, err=%+v", testCase.expr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := schedule.Next(testCase.from); !got.Equal(testCase.expected) {
+			t.Errorf("expr=%q, got=%v, expected=%v", testCase.expr, got, testCase.expected)
+		}
+	}
+}
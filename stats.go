@@ -0,0 +1,136 @@
+package taskgroup
+
+import (
+	"sync"
+	"time"
+)
+
+// RunStats 表示任务组一次运行的统计
+type RunStats struct {
+	StartedAt time.Time     // 运行开始时间
+	Elapsed   time.Duration // 运行耗时
+	Tasks     int           // 待执行的任务数
+	Succeeded int           // 执行成功的任务数
+	Failed    int           // 执行失败的任务数
+
+	TagStats   map[string]TagStats // 各标签任务的统计
+	Stragglers []Straggler         // 落后任务，需开启[WithStragglerDetection]
+
+	Children map[uint32]*RunStats // 各子任务组(见[NewGroupTask])的运行统计
+
+	Discarded     int     // 被清理的任务结果数，见[WithCleanup]
+	CleanupErrors []error // 清理任务结果时的错误信息
+
+	Throttled     int           // 因内存压力而暂停分发的任务数，见[WithMemoryThrottle]
+	ThrottledWait time.Duration // 因内存压力而暂停分发的累计等待时长
+}
+
+// Progress 表示任务组运行中的进度快照
+type Progress struct {
+	Tasks     int // 待执行的任务数
+	Running   int // 执行中的任务数
+	Succeeded int // 执行成功的任务数
+	Failed    int // 执行失败的任务数
+}
+
+// TagStats 表示同一标签任务的统计
+type TagStats struct {
+	Tasks     int           // 已开始执行的任务数
+	TotalWait time.Duration // 任务从入队到开始执行的累计等待时长
+	MaxWait   time.Duration // 任务从入队到开始执行的最大等待时长
+}
+
+// runStats 运行期间的统计
+type runStats struct {
+	clock     Clock
+	startedAt time.Time
+
+	mu      sync.Mutex
+	stats   RunStats
+	running int // 执行中的任务数
+}
+
+func newRunStats(taskNums int, clock Clock) *runStats {
+	startedAt := clock.Now()
+	return &runStats{clock: clock, startedAt: startedAt, stats: RunStats{StartedAt: startedAt, Tasks: taskNums}}
+}
+
+// addTasks 记录运行期间添加的`n`个任务，见[ShedBlock]
+func (rs *runStats) addTasks(n int) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.stats.Tasks += n
+}
+
+// observeStart 记录任务`task`开始执行，及其开始执行前的等待时长`wait`，仅带有标签的任务需要记录等待时长
+func (rs *runStats) observeStart(task *Task, wait time.Duration) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.running++
+	if len(task.limitTags) == 0 {
+		return
+	}
+
+	if rs.stats.TagStats == nil {
+		rs.stats.TagStats = make(map[string]TagStats)
+	}
+	for _, tag := range task.limitTags {
+		tagStats := rs.stats.TagStats[tag]
+		tagStats.Tasks++
+		tagStats.TotalWait += wait
+		tagStats.MaxWait = If(wait > tagStats.MaxWait, wait, tagStats.MaxWait).(time.Duration)
+		rs.stats.TagStats[tag] = tagStats
+	}
+}
+
+// observeDone 记录任务的执行状态`err`
+func (rs *runStats) observeDone(err error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.running--
+	if err != nil {
+		rs.stats.Failed++
+		return
+	}
+	rs.stats.Succeeded++
+}
+
+// progress 获取运行进度的快照
+func (rs *runStats) progress() Progress {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return Progress{Tasks: rs.stats.Tasks, Running: rs.running, Succeeded: rs.stats.Succeeded, Failed: rs.stats.Failed}
+}
+
+// finish 结束统计，并返回统计快照
+func (rs *runStats) finish() *RunStats {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	stats := rs.stats
+	stats.Elapsed = rs.clock.Now().Sub(rs.startedAt)
+	return &stats
+}
+
+// setLiveStats 记录运行中的统计`live`，以获取运行进度
+func (tg *TaskGroup) setLiveStats(live *runStats) {
+	tg.statsMu.Lock()
+	defer tg.statsMu.Unlock()
+	tg.live = live
+}
+
+func (tg *TaskGroup) setStats(stats *RunStats) {
+	tg.statsMu.Lock()
+	defer tg.statsMu.Unlock()
+	tg.stats, tg.live = stats, nil
+}
+
+// Stats 获取任务组最近一次运行的统计，还未运行时，返回`nil`
+func (tg *TaskGroup) Stats() *RunStats {
+	if tg == nil {
+		return nil
+	}
+
+	tg.statsMu.Lock()
+	defer tg.statsMu.Unlock()
+	return tg.stats
+}
@@ -0,0 +1,229 @@
+package taskgroup
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultStragglerMultiple = 3.0                   // 默认的落后任务判定倍数
+	stragglerMinSamples      = 3                     // 判定落后任务所需的最少已完成任务数
+	stragglerCheckInterval   = 10 * time.Millisecond // 落后任务的检测周期
+)
+
+// WithStragglerDetection 开启落后任务检测，当任务的执行时长超过已完成任务耗时中位数的`multiple`倍时，判定为落后任务，
+// 并记录在运行统计[RunStats.Stragglers]中，`multiple`不大于1时，默认为3
+//
+// 当`speculative`为`true`且存在空闲的`worker`时，将对落后任务进行推测执行(再次执行该任务)，并采用先执行完成的结果，
+// 此时，任务方法需是幂等的；先执行完成后，未被采用的执行的`ctx`将被取消，其执行结果将交由清理方法清理(见[WithCleanup])，
+// 任务组不会等待其结束；由[NewGroupTask]创建的任务不会被推测执行
+func WithStragglerDetection(multiple float64, speculative bool) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.stragglerMultiple = If(multiple > 1, multiple, defaultStragglerMultiple).(float64)
+		tg.speculative = speculative
+	}
+}
+
+// Straggler 表示一个落后任务
+type Straggler struct {
+	FNO            uint32        // 任务编号(标识)
+	Name           string        // 任务名称
+	Elapsed        time.Duration // 被判定为落后任务时，任务已执行的时长
+	Median         time.Duration // 被判定为落后任务时，已完成任务耗时的中位数
+	Speculated     bool          // 是否进行了推测执行
+	SpeculationWon bool          // 推测执行是否先于原执行完成
+}
+
+// stragglerDetector 落后任务检测器
+type stragglerDetector struct {
+	multiple    float64
+	speculative bool
+	clock       Clock
+	cleaner     *resultCleaner // 清理未被采用的执行结果
+	idleWorkers atomic.Int32   // 已无任务可执行的`workers`数量
+
+	mu         sync.Mutex
+	durations  []time.Duration // 已完成任务的耗时
+	running    map[*execution]struct{}
+	stragglers []Straggler
+}
+
+// execution 任务的一次执行(包括其推测执行)
+type execution struct {
+	task      *Task
+	call      func(ctx context.Context, task *Task) (interface{}, error) // 任务方法的调用方式
+	startedAt time.Time
+	straggler int // 在落后任务列表中的位置，不是落后任务时为-1
+
+	mu       sync.Mutex
+	cancels  map[uint32]context.CancelFunc // 各次执行的`ctx`的取消方法
+	finished bool
+	done     chan struct{}
+	result   interface{}
+	err      error
+	attempt  uint32 // 被采用的执行是第几次执行
+}
+
+// start 以派生自`ctx`的独立`ctx`开始任务的第`attempt`次执行，任务已执行完成时，不再执行
+func (e *execution) start(ctx context.Context, attempt uint32, cleaner *resultCleaner) bool {
+	e.mu.Lock()
+	if e.finished {
+		e.mu.Unlock()
+		return false
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	e.cancels[attempt] = cancel
+	e.mu.Unlock()
+
+	go func() {
+		result, err := e.call(ctx, e.task)
+		if !e.finish(result, err, attempt) {
+			cleaner.discard(newTaskResult(e.task, result, err))
+		}
+	}()
+	return true
+}
+
+// finish 记录先执行完成的结果，并取消其余的执行，结果未被采用时，返回`false`
+func (e *execution) finish(result interface{}, err error, attempt uint32) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.finished {
+		return false
+	}
+
+	e.finished = true
+	e.result, e.err, e.attempt = result, err, attempt
+	for other, cancel := range e.cancels {
+		if other != attempt {
+			cancel()
+		}
+	}
+	close(e.done)
+	return true
+}
+
+// newStragglerDetector 按照任务组的配置创建落后任务检测器，并在`ctx`结束前周期性的检测
+func (tg *TaskGroup) newStragglerDetector(ctx context.Context, cleaner *resultCleaner) *stragglerDetector {
+	if tg.stragglerMultiple == 0 {
+		return nil
+	}
+
+	d := &stragglerDetector{
+		multiple:    tg.stragglerMultiple,
+		speculative: tg.speculative,
+		clock:       tg.runClock(),
+		cleaner:     cleaner,
+		running:     make(map[*execution]struct{}),
+	}
+	ticker := d.clock.NewTicker(stragglerCheckInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C():
+				d.check(ctx, now)
+			}
+		}
+	}()
+	return d
+}
+
+// execute 通过`call`执行任务`task`，推测执行时，任务将在独立的协程中执行，以便采用先执行完成的结果
+func (d *stragglerDetector) execute(ctx context.Context, task *Task, call func(ctx context.Context, task *Task) (interface{}, error)) (interface{}, uint32, error) {
+	e := &execution{task: task, call: call, startedAt: d.clock.Now(), straggler: -1, cancels: make(map[uint32]context.CancelFunc), done: make(chan struct{})}
+	d.mu.Lock()
+	d.running[e] = struct{}{}
+	d.mu.Unlock()
+
+	if d.speculative {
+		e.start(ctx, 1, d.cleaner)
+	} else {
+		result, err := call(ctx, task)
+		e.finish(result, err, 1)
+	}
+	<-e.done
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.running, e)
+	d.durations = append(d.durations, d.clock.Now().Sub(e.startedAt))
+	if e.straggler >= 0 && e.attempt > 1 {
+		d.stragglers[e.straggler].SpeculationWon = true
+	}
+	return e.result, e.attempt, e.err
+}
+
+// check 检测执行中的落后任务
+func (d *stragglerDetector) check(ctx context.Context, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.durations) < stragglerMinSamples {
+		return
+	}
+
+	durations := append([]time.Duration(nil), d.durations...)
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	median := durations[len(durations)/2]
+	threshold := time.Duration(float64(median) * d.multiple)
+	for e := range d.running {
+		if e.straggler < 0 {
+			elapsed := now.Sub(e.startedAt)
+			if elapsed <= threshold {
+				continue
+			}
+			e.straggler = len(d.stragglers)
+			d.stragglers = append(d.stragglers, Straggler{FNO: e.task.fNO, Name: e.task.name, Elapsed: elapsed, Median: median})
+		}
+		// 落后任务被判定时无空闲的`worker`，则，待出现空闲的`worker`时再进行推测执行
+		if d.speculative && e.task.child == nil && !d.stragglers[e.straggler].Speculated {
+			d.speculate(ctx, e)
+		}
+	}
+}
+
+// speculate 占用一个空闲的`worker`对落后任务进行推测执行，调用方需持有锁
+func (d *stragglerDetector) speculate(ctx context.Context, e *execution) {
+	if d.idleWorkers.Add(-1) < 0 {
+		d.idleWorkers.Add(1)
+		return
+	}
+
+	// 推测执行期间占用该空闲的`worker`，直至任一执行完成
+	if !e.start(ctx, 2, d.cleaner) {
+		d.idleWorkers.Add(1)
+		return
+	}
+	d.stragglers[e.straggler].Speculated = true
+	go func() {
+		<-e.done
+		d.idleWorkers.Add(1)
+	}()
+}
+
+// idle 标记一个`worker`已无任务可执行
+func (d *stragglerDetector) idle() {
+	if d == nil {
+		return
+	}
+	d.idleWorkers.Add(1)
+}
+
+// list 获取所有的落后任务
+func (d *stragglerDetector) list() []Straggler {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Straggler(nil), d.stragglers...)
+}
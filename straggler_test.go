@@ -0,0 +1,97 @@
+package taskgroup_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_straggler(t *testing.T) {
+	const slowFNO = 1
+	testCases := []struct {
+		speculative bool
+		maxElapsed  time.Duration
+	}{
+		{false, time.Hour},
+		{true, 250 * time.Millisecond},
+	}
+	for _, testCase := range testCases {
+		var calls atomic.Int32
+		tasks := []*taskgroup.Task{
+			taskgroup.NewTask(slowFNO, func() (interface{}, error) {
+				if calls.Add(1) == 1 { // 仅首次执行缓慢
+					time.Sleep(500 * time.Millisecond)
+				}
+				return "slow", nil
+			}, true),
+		}
+		for i := 2; i <= 10; i++ {
+			tasks = append(tasks, taskgroup.NewTask(uint32(i), func() (interface{}, error) {
+				time.Sleep(2 * time.Millisecond)
+				return "fast", nil
+			}, false))
+		}
+
+		tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(3), taskgroup.WithStragglerDetection(3, testCase.speculative))
+		start := time.Now()
+		results, err := tg.AddTask(tasks...).Run()
+		if elapsed := time.Since(start); err != nil || len(results) != len(tasks) || elapsed > testCase.maxElapsed {
+			t.Fatalf("speculative=%v, elapsed=%v, results=%+v, err=%+v", testCase.speculative, elapsed, results, err)
+		}
+
+		stragglers := tg.Stats().Stragglers
+		if len(stragglers) != 1 || stragglers[0].FNO != slowFNO || stragglers[0].Elapsed <= stragglers[0].Median {
+			t.Fatalf("speculative=%v, stragglers=%+v", testCase.speculative, stragglers)
+		}
+		if stragglers[0].Speculated != testCase.speculative || stragglers[0].SpeculationWon != testCase.speculative {
+			t.Errorf("speculative=%v, straggler=%+v", testCase.speculative, stragglers[0])
+		}
+	}
+}
+
+func TestTaskGroupRun_speculationLoser(t *testing.T) {
+	var (
+		calls  atomic.Int32
+		loser  resource
+		winner resource
+		loserC = make(chan struct{})
+	)
+	tasks := []*taskgroup.Task{
+		taskgroup.NewContextTask(1, func(ctx context.Context) (interface{}, error) {
+			if calls.Add(1) == 1 { // 首次执行缓慢，直至被取消
+				<-ctx.Done()
+				defer close(loserC)
+				return &loser, ctx.Err()
+			}
+			return &winner, nil
+		}, true),
+	}
+	for i := 2; i <= 10; i++ {
+		tasks = append(tasks, taskgroup.NewTask(uint32(i), func() (interface{}, error) {
+			time.Sleep(2 * time.Millisecond)
+			return "fast", nil
+		}, false))
+	}
+
+	tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(3), taskgroup.WithStragglerDetection(3, true), taskgroup.WithCleanup(nil))
+	results, err := tg.AddTask(tasks...).Run()
+	if err != nil || results[1].Result() != &winner {
+		t.Fatalf("results=%+v, err=%+v", results, err)
+	}
+	// 未被采用的执行被取消，且其结果被清理，被采用的结果不被清理
+	select {
+	case <-loserC:
+	case <-time.After(time.Second):
+		t.Fatal("speculation loser not cancelled")
+	}
+	deadline := time.Now().Add(time.Second)
+	for !loser.closed.Load() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !loser.closed.Load() || winner.closed.Load() {
+		t.Errorf("loser closed=%v, winner closed=%v", loser.closed.Load(), winner.closed.Load())
+	}
+}
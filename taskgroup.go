@@ -6,28 +6,121 @@ package taskgroup
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"reflect"
 	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // TaskGroup 表示可将多个任务进行安全并发执行的一个对象
 type TaskGroup struct {
-	workerNums uint32 // 工作组数量（协程数）
+	workerNums           uint32 // 工作组数量（协程数）
+	configuredWorkerNums uint32 // 指定的协程数，执行前不会被调整，见[TaskGroup.Workflow]
 
 	initOnce       sync.Once
 	runExactlyOnce sync.Once
 
 	fNOs  map[uint32]struct{}
 	tasks []*Task
+
+	checkpointer Checkpointer // 任务执行结果的检查点记录器
+	resume       bool         // 是否从检查点恢复(跳过已记录为执行成功的任务)
+
+	tagLimits map[string]uint32 // 各标签任务的并发上限
+	selector  map[string]string // 标签选择器，仅执行与其匹配的任务
+
+	resultCache ResultCache // 任务结果缓存
+
+	futures futureSet // 由[TaskGroup.Submit]添加的任务的句柄
+
+	recorder Recorder  // 运行事件的记录器
+	replay   *replayer // 按照记录的事件回放运行
+
+	clock      Clock // 任务组运行时使用的时钟，为`nil`时，表示[SystemClock]
+	sequential bool  // 是否在单个协程上按确定的顺序依次执行任务
+
+	faultSeed int64   // 故障注入的随机数种子
+	faults    []Fault // 故障注入的规则
+
+	cleanup CleanupFunc // 被丢弃的任务结果的清理方法，为`nil`时，表示不清理
+
+	classWorkers map[TaskClass]uint32 // 各类别任务的协程数
+
+	watchdogThreshold time.Duration       // 看门狗的阈值，为0时，表示不开启看门狗
+	watchdogReport    func(hung HungTask) // 看门狗的报告方式
+	watchdogAbandon   bool                // 是否放弃执行超时的任务
+
+	logger    *slog.Logger // 任务日志记录器，为`nil`时，表示不记录日志
+	logLevels *LogLevels   // 各类任务日志的级别，为`nil`时，表示[DefaultLogLevels]
+
+	metrics *groupMetrics // 任务组的运行指标，为`nil`时，表示不记录指标
+
+	memorySoftLimit uint64      // 内存使用量的软上限，为0时，表示不限流
+	memoryProbe     MemoryProbe // 内存使用量的探针
+	throttleBypass  bool        // 必要成功的任务是否不受限流影响
+
+	maxQueuedTasks uint32     // 待执行任务数的上限，为0时，表示不限制
+	shedPolicy     ShedPolicy // 待执行任务数达到上限时的丢弃策略
+	shedTasks      []*Task    // 被丢弃的任务
+	feed           *taskFeed  // 运行期间持续添加的任务，仅[ShedBlock]策略
+
+	stragglerMultiple float64 // 落后任务的判定倍数，为0时，表示不开启落后任务检测
+	speculative       bool    // 是否对落后任务进行推测执行
+
+	statsMu sync.Mutex
+	stats   *RunStats // 最近一次运行的统计
+	live    *runStats // 运行中的统计，未运行时为`nil`
 }
 
 // TaskFunc 任务函数的签名
 type TaskFunc func() (interface{}, error)
 
+// ContextTaskFunc 可感知任务组运行上下文的任务函数的签名，任务组执行失败或被取消时，`ctx`将被取消
+type ContextTaskFunc func(ctx context.Context) (interface{}, error)
+
 type Task struct {
-	fNO         uint32   // 任务编号(标识)
-	f           TaskFunc // 任务方法
-	mustSuccess bool     // 任务必须执行成功，否则整个任务组将会立即结束，且失败(将会返回第一个必须成功任务的失败结果)
+	fNO         uint32          // 任务编号(标识)
+	f           TaskFunc        // 任务方法
+	cf          ContextTaskFunc // 可感知运行上下文的任务方法，与`f`二者有其一
+	child       *TaskGroup      // 作为任务执行的子任务组
+	mustSuccess bool            // 任务必须执行成功，否则整个任务组将会立即结束，且失败(将会返回第一个必须成功任务的失败结果)
+	limitTags   []string        // 用以限制并发量的任务标签，见[WithTagLimit]
+	priority    int             // 任务优先级，值越大优先级越高
+
+	name        string            // 任务名称
+	description string            // 任务描述
+	labels      map[string]string // 任务的元数据标签(键值对)，可用以筛选执行结果及选择待执行的任务
+
+	cacheKey string // 任务结果的缓存键，为空时，表示不缓存
+
+	cleanup CleanupFunc // 被丢弃的任务结果的清理方法
+
+	class TaskClass // 任务类别
+
+	resultType reflect.Type // 任务结果的类型，用以解码来自检查点或磁盘缓存的任务结果
+
+	timeout time.Duration // 任务单次执行的超时时长，为0时，表示不超时
+	retries uint32        // 任务执行失败后的最大重试次数
+
+	dependencies []uint32 // 任务所依赖的任务编号
+	registered   string   // 任务方法在注册表中的名称，见[Register]
+}
+
+// TaskOption 表示任务默认行为的修改
+type TaskOption func(*Task)
+
+// WithTags 指定任务的标签`tags`，可结合[WithTagLimit]限制同一标签任务的并发量
+//
+// 标签仅用于调度(并发量限制)，与[WithLabels]指定的元数据标签(键值对)相互独立
+func WithTags(tags ...string) TaskOption {
+	return func(t *Task) {
+		if t == nil {
+			return
+		}
+		t.limitTags = append(t.limitTags, tags...)
+	}
 }
 
 // Option 表示任务组对象默认行为的修改
@@ -42,12 +135,25 @@ type Option func(*TaskGroup)
 // 2. 任务的性质：任务可能是 I/O 密集型（如网络请求或磁盘读写）或 CPU 密集型（如复杂的数学计算）。对于 I/O 密集型任务，增加 worker 数量可以更有效地利用等待时间，因为当一个 worker 在等待 I/O 操作完成时，其他 worker 可以继续执行。然而，对于 CPU 密集型任务，增加 worker 数量可能会导致 CPU 资源耗尽，从而降低性能。
 //
 // 3. 任务的粒度：任务的粒度（即每个任务所需的时间）也会影响 worker 的数量。如果任务粒度很小，那么可能需要更多的 worker 来确保 CPU 始终保持忙碌状态。但是，如果任务粒度很大，那么少量的 worker 就足以处理所有任务，增加 worker 数量可能是不必要的。
+//
+// 同一任务组中同时存在`CPU`密集型与`IO`密集型任务时，可通过[WithClass]与[WithClassWorkers]使其在各自独立的`workers`上执行
 func WithWorkerNums(workerNums uint32) Option {
 	return func(tg *TaskGroup) {
 		if tg == nil {
 			return
 		}
-		tg.workerNums = workerNums
+		tg.workerNums, tg.configuredWorkerNums = workerNums, workerNums
+	}
+}
+
+// WithSequential 在单个协程上按确定的顺序(必要成功的任务优先，其次按任务编号升序，被依赖的任务总在依赖其的任务之前)依次执行任务，
+// 此时，[WithWorkerNums]指定的协程数将被忽略，多用于需要稳定复现执行顺序的单元测试
+func WithSequential() Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.sequential = true
 	}
 }
 
@@ -67,8 +173,34 @@ func NewTaskGroup(opts ...Option) *TaskGroup {
 
 // NewTask 创建一个任务，`fNO`用以表示任务`f`的唯一标识, `mustSuccess`则表示该任务`f`是否为必须成功，当`true`时,
 // 且任务`f`执行失败，表示整个任务组将执行失败
-func NewTask(fNO uint32 /* 任务唯一标识 */, f TaskFunc /* 任务执行方法 */, mustSuccess bool /* 标识任务是否必须执行成功 */) *Task {
-	return &Task{fNO, f, mustSuccess}
+func NewTask(fNO uint32 /* 任务唯一标识 */, f TaskFunc /* 任务执行方法 */, mustSuccess bool /* 标识任务是否必须执行成功 */, opts ...TaskOption) *Task {
+	t := &Task{fNO: fNO, f: f, mustSuccess: mustSuccess}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(t)
+		}
+	}
+	return t
+}
+
+// NewContextTask 同[NewTask]，创建一个可感知任务组运行上下文的任务
+func NewContextTask(fNO uint32, f ContextTaskFunc, mustSuccess bool, opts ...TaskOption) *Task {
+	t := NewTask(fNO, nil, mustSuccess, opts...)
+	t.cf = f
+	return t
+}
+
+// runnable 任务是否有可执行的任务方法
+func (t *Task) runnable() bool {
+	return t.f != nil || t.cf != nil
+}
+
+// call 执行任务方法
+func (t *Task) call(ctx context.Context) (interface{}, error) {
+	if t.cf != nil {
+		return t.cf(ctx)
+	}
+	return t.f()
 }
 
 // AddTask 向任务组中添加若干待执行的任务`tasks`
@@ -79,6 +211,16 @@ func (tg *TaskGroup) AddTask(tasks ...*Task) *TaskGroup {
 		return nil
 	}
 
+	if tg.feed != nil {
+		tg.feedTasks(tasks, false)
+		return tg
+	}
+	tg.addTask(tasks, tg.shed)
+	return tg
+}
+
+// addTask 添加若干任务`tasks`，待执行的任务数已达上限时，按照丢弃策略进行准入控制，未被接纳的任务交由`reject`处理
+func (tg *TaskGroup) addTask(tasks []*Task, reject func(task *Task)) {
 	if tg.fNOs == nil || len(tasks) > cap(tg.tasks) {
 		tg.initOnce.Do(func() {
 			preAllocatedCapacity := (len(tasks) + 1) * 2
@@ -88,18 +230,21 @@ func (tg *TaskGroup) AddTask(tasks ...*Task) *TaskGroup {
 	}
 
 	for i := 0; i < len(tasks); i++ {
-		if tasks[i] == nil || tasks[i].f == nil {
+		if tasks[i] == nil || !tasks[i].runnable() {
 			continue
 		}
 
 		if _, exist := tg.fNOs[tasks[i].fNO]; exist { // 已经有相同的任务了
-			panic(fmt.Sprintf("AddTask: Already have the same Task %d", tasks[i].fNO))
+			panic(fmt.Sprintf("AddTask: Already have the same Task %s", tasks[i].ident()))
 		}
 
+		if tg.queueFull() && !tg.admit(tasks[i]) {
+			reject(tasks[i])
+			continue
+		}
 		tg.fNOs[tasks[i].fNO] = struct{}{}
 		tg.tasks = append(tg.tasks, tasks[i])
 	}
-	return tg
 }
 
 // RunExactlyOnce 启动并运行任务组中的所有任务(运行当且仅当一次)
@@ -116,47 +261,121 @@ func (tg *TaskGroup) RunExactlyOnce() (result map[uint32]*TaskResult, err error)
 
 // Run 启动并运行任务组中的所有任务
 //
-// 当返回`non-nil`错误时，则，返回的任务执行结果将不可信
+// 当返回`non-nil`错误时，则，返回的任务执行结果将不可信，因必要成功的任务失败而返回的错误为[*TaskError]
 func (tg *TaskGroup) Run() (map[uint32]*TaskResult, error) {
+	return tg.RunContext(context.Background())
+}
+
+// RunContext 同[TaskGroup.Run]，当`ctx`被取消时，将停止执行所有还未启动的任务，并返回`ctx`被取消的原因
+func (tg *TaskGroup) RunContext(ctx context.Context) (taskResults map[uint32]*TaskResult, err error) {
 	if tg == nil {
 		return nil, nil
 	}
+	defer func() { tg.settleFutures(taskResults, err) }()
+
+	// 运行期间仍可添加任务(见[ShedBlock])时，准备运行期间暂停添加任务
+	feeding := tg.feed.open()
+	defer tg.feed.release()
 
 	taskNums := len(tg.tasks)
-	if taskNums == 0 {
+	if taskNums == 0 && len(tg.shedTasks) == 0 && !feeding {
 		return nil, nil
 	}
 
 	// 执行任务前的若干准备工作
-	tg.prepare()
+	tg.prepare(feeding)
+
+	// 仅执行与标签选择器匹配的任务
+	selectedTasks, shedTasks := tg.selectTasks(tg.tasks), tg.selectTasks(tg.shedTasks)
+	// 从检查点恢复时，已记录为执行成功的任务无需再次执行
+	recovered, pendingTasks, err := tg.restore(selectedTasks)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range shedTasks {
+		recovered[task.fNO] = newTaskResult(task, nil, &TaskError{FNO: task.fNO, Name: task.name, MustSuccess: task.mustSuccess, Err: ErrQueueFull})
+		recovered[task.fNO].shed = true
+	}
+	// 必要成功的任务被丢弃时，任务组将无法执行成功
+	if err = shedErr(shedTasks); err != nil || len(pendingTasks) == 0 && !feeding {
+		return recovered, err
+	}
+
+	// 排列任务前检查依赖环，以免按依赖排列时无法结束
+	deps, err := newDependencyTracker(pendingTasks, recovered, feeding)
+	if err != nil {
+		return nil, err
+	}
+
+	workerNums := tg.workerNums
+	switch {
+	case tg.replay != nil: // 回放时，按照记录的顺序依次执行任务
+		pendingTasks, workerNums = tg.replay.arrange(pendingTasks), 1
+	case tg.sequential:
+		sortSequential(pendingTasks)
+		workerNums = 1
+	}
 
+	taskNums = len(pendingTasks)
 	var (
-		tasks   = make(chan *Task, taskNums)
 		results = make(chan *TaskResult, taskNums)
 
 		wg   sync.WaitGroup
 		once sync.Once
 	)
-	ctx, cancel := context.WithCancelCause(context.Background())
+	ctx, cancel := context.WithCancelCause(ctx)
 	defer cancel(nil) // 避免`ctx`相关的资源泄露(channel, goroutine等)
-	// 启动`workers`
-	for i := 1; i <= int(tg.workerNums); i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if err := tg.worker(ctx, tasks, results); err != nil {
-				once.Do(func() {
-					cancel(err)
-				})
-			}
-		}()
+	cleaner := tg.newResultCleaner(pendingTasks)
+	r := &runner{
+		results:    results,
+		clock:      tg.runClock(),
+		stats:      newRunStats(taskNums, tg.runClock()),
+		stragglers: tg.newStragglerDetector(ctx, cleaner),
+		cache:      tg.newTaskCache(),
+		events:     tg.newEventRecorder(),
+		replay:     tg.replay,
+		faults:     tg.newFaultInjector(),
+		cleaner:    cleaner,
+		throttle:   tg.newMemoryThrottle(),
+		logger:     tg.newTaskLogger(),
+		metrics:    tg.metrics,
+		deps:       deps,
+		futures:    &tg.futures,
 	}
-
-	// 发送任务到所有的`workers`中
-	for i := 0; i < taskNums; i++ {
-		tasks <- tg.tasks[i]
+	fail := func(err error) {
+		once.Do(func() {
+			r.events.groupCancelled(err)
+			cancel(err)
+		})
+	}
+	r.watchdog = tg.newWatchdog(ctx, fail, cleaner)
+	// 看门狗在超时之内，以记录实际执行任务的协程
+	r.call = r.withTimeout(r.watchdog.wrap(r.faults.call))
+	tg.setLiveStats(r.stats)
+	r.events.queued(pendingTasks)
+	var feed *pendingDispatcher
+	if feeding {
+		feed = newPendingDispatcher(ctx, pendingTasks, tg.tagLimits, deps)
+		feed.feed(tg.maxQueuedTasks)
+		tg.feed.attach(r.newFeedRun(tg, feed, pendingTasks))
+	}
+	// 发送任务到各类别的分发器中，并启动`workers`
+	var workerID int
+	for _, pool := range tg.newWorkerPools(ctx, pendingTasks, workerNums, deps, feed) {
+		for i := 0; i < int(pool.workers); i++ {
+			workerID++
+			wg.Add(1)
+			go func(tasks dispatcher, workerID int) {
+				defer wg.Done()
+				r.metrics.observeWorker(1)
+				defer r.metrics.observeWorker(-1)
+				if err := r.worker(ctx, tasks, workerID); err != nil {
+					fail(err)
+				}
+				r.stragglers.idle()
+			}(pool.tasks, workerID)
+		}
 	}
-	close(tasks)
 
 	go func() {
 		wg.Wait()
@@ -164,18 +383,69 @@ func (tg *TaskGroup) Run() (map[uint32]*TaskResult, error) {
 	}()
 
 	// 从所有的`workers`中收集结果
-	taskResults := make(map[uint32]*TaskResult, taskNums)
+	taskResults = make(map[uint32]*TaskResult, taskNums+len(recovered))
+	for fNO, result := range recovered {
+		taskResults[fNO] = result
+	}
 	for result := range results {
 		taskResults[result.fNO] = result
+		tg.futures.get(result.fNO).resolve(result, nil)
+		if tg.checkpointer == nil {
+			continue
+		}
+		if err := tg.checkpointer.Record(result); err != nil {
+			fail(fmt.Errorf("Run: checkpoint task %d: %w", result.fNO, err))
+		}
+	}
+	if feeding {
+		pendingTasks = tg.feed.detach()
+	}
+	if err = context.Cause(ctx); err != nil { // 如，`ctx`被调用方取消
+		r.events.groupCancelled(err)
+		r.cleaner.discardAll(taskResults)
+		r.logger.cancelled(pendingTasks, err)
+	}
+	r.events.cancelled(pendingTasks)
+	tg.setStats(r.finish(pendingTasks))
+	return taskResults, err
+}
+
+// restore 从检查点中恢复任务`tasks`中已执行成功的任务结果，并返回仍需执行的任务
+func (tg *TaskGroup) restore(tasks []*Task) (map[uint32]*TaskResult, []*Task, error) {
+	if tg.checkpointer == nil || !tg.resume {
+		return make(map[uint32]*TaskResult, len(tg.shedTasks)), tasks, nil
+	}
+
+	recorded, err := tg.checkpointer.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Run: load checkpoint: %w", err)
 	}
-	return taskResults, context.Cause(ctx)
+	var (
+		recovered    = make(map[uint32]*TaskResult, len(recorded))
+		pendingTasks = make([]*Task, 0, len(tasks))
+	)
+	for _, task := range tasks {
+		if result, has := recorded[task.fNO]; has && result.Error() == nil {
+			// 按任务的结果类型重新解码，无法解码(如，结果类型已变更)时，需再次执行任务
+			if result.encoded != nil && task.resultType != nil && result.decodeResult(result.encoded, task.resultType) != nil {
+				pendingTasks = append(pendingTasks, task)
+				continue
+			}
+			result.name, result.description, result.labels = task.name, task.description, task.labels
+			recovered[task.fNO] = result
+			continue
+		}
+		pendingTasks = append(pendingTasks, task)
+	}
+	return recovered, pendingTasks, nil
 }
 
-func (tg *TaskGroup) prepare() {
+func (tg *TaskGroup) prepare(feeding bool) {
 	// 优先执行必要成功的任务，当同一个goroutine执行多个任务时，如出现了必要成功任务失败时，可提前结束goroutine，即，无需后续任务执行了
 	rearrangeTasks(tg.tasks)
-	// 调整工作组中的协程量
-	WithWorkerNums(adjustWorkerNums(tg.workerNums, uint32(len(tg.tasks))))(tg)
+	// 调整工作组中的协程量，运行期间仍可添加任务时，按待执行任务数的上限调整
+	taskNums := If(feeding, tg.maxQueuedTasks, uint32(len(tg.tasks))).(uint32)
+	tg.workerNums = adjustWorkerNums(tg.workerNums, taskNums)
 }
 
 // rearrangeTasks 任务顺序重排
@@ -190,6 +460,17 @@ func rearrangeTasks(tasks []*Task) {
 	})
 }
 
+// sortSequential 将任务按依次执行的顺序排列
+func sortSequential(tasks []*Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].mustSuccess != tasks[j].mustSuccess {
+			return tasks[i].mustSuccess
+		}
+		return tasks[i].fNO < tasks[j].fNO
+	})
+	sortDependencies(tasks)
+}
+
 // adjustWorkerNums 调整工作组中的协程量
 //
 //go:nosplit
@@ -204,24 +485,135 @@ func adjustWorkerNums(workerNums, taskNums uint32) uint32 {
 	return workerNums
 }
 
-// worker 若干个任务将会共享在一个协程上执行任务
-func (tg *TaskGroup) worker(ctx context.Context, tasks <-chan *Task, results chan<- *TaskResult) error {
-	for task := range tasks {
-		select {
-		case <-ctx.Done(): // 接收到`ctx`被取消的信号，即刻停止后续任务的执行
-			return context.Cause(ctx)
-		default:
-			result, err := task.f()
-			if task.mustSuccess && err != nil {
-				return err
-			}
-			// 防止向关闭的`channel`中写入数据
-			if context.Cause(ctx) == nil {
-				results <- &TaskResult{task.fNO, result, err}
-			}
+// runner 表示任务组单次运行期间的状态
+type runner struct {
+	results    chan<- *TaskResult
+	clock      Clock
+	stats      *runStats
+	stragglers *stragglerDetector // 未开启落后任务检测时为`nil`
+	cache      *taskCache         // 未指定结果缓存时为`nil`
+	events     *eventRecorder     // 未指定事件记录器时为`nil`
+	replay     *replayer          // 非回放时为`nil`
+	faults     *faultInjector     // 未开启故障注入时为`nil`
+	cleaner    *resultCleaner     // 未指定清理方法时为`nil`
+	throttle   *memoryThrottle    // 未开启内存限流时为`nil`
+	watchdog   *watchdog          // 未开启看门狗时为`nil`
+	logger     *taskLogger        // 未指定日志记录器时为`nil`
+	metrics    *groupMetrics      // 未指定指标注册表时为`nil`
+	deps       *dependencyTracker // 没有任务存在依赖时为`nil`
+	futures    *futureSet         // 由[TaskGroup.Submit]添加的任务的句柄
+
+	call func(ctx context.Context, task *Task) (interface{}, error) // 任务的执行方法(含故障注入、超时与看门狗)，每次运行仅构建一次
+}
+
+// worker 若干个任务将会共享在一个协程上执行任务，任务取自分发器`tasks`，`workerID`为协程的编号(从1开始)
+func (r *runner) worker(ctx context.Context, tasks dispatcher, workerID int) error {
+	for {
+		// 接收到`ctx`被取消的信号时，分发器将即刻停止后续任务的分发
+		task, ok := tasks.next(ctx)
+		if !ok {
+			return nil
+		}
+		// 内存压力较大时，暂缓执行任务
+		if !r.throttle.admit(ctx, task) {
+			return nil
+		}
+		// 任务仅在其依赖均执行结束后被分发，任一依赖执行失败时，任务不再执行
+		depErr := r.deps.failed(task)
+		startedAt := r.clock.Now()
+		r.stats.observeStart(task, startedAt.Sub(r.stats.startedAt))
+		r.metrics.observeStart(startedAt.Sub(r.stats.startedAt))
+		r.events.started(task, workerID)
+		r.logger.started(ctx, task, workerID)
+		var result *TaskResult
+		if depErr != nil {
+			result = newTaskResult(task, nil, depErr)
+		} else {
+			result = r.execute(ctx, task, workerID)
+		}
+		r.deps.finish(result)
+		duration := r.clock.Now().Sub(startedAt)
+		r.events.finished(result, workerID)
+		r.logger.finished(ctx, result, workerID, duration)
+		r.metrics.observeDone(result.err, duration)
+		// 任务执行失败的错误信息均以[*TaskError]包装
+		if result.err != nil {
+			result.err = &TaskError{FNO: task.fNO, Name: task.name, MustSuccess: task.mustSuccess, Attempt: result.attempt, StartedAt: startedAt, Duration: duration, Err: result.err}
 		}
+		tasks.done(task)
+		r.stats.observeDone(result.err)
+		if task.mustSuccess && result.err != nil {
+			r.cleaner.discard(result)
+			r.futures.get(task.fNO).resolve(result, result.err)
+			return result.err
+		}
+		// 防止向关闭的`channel`中写入数据
+		if context.Cause(ctx) != nil {
+			r.cleaner.discard(result)
+			continue
+		}
+		r.results <- result
 	}
-	return nil
+}
+
+// execute 在`worker`上执行任务`task`，并返回任务的执行结果
+func (r *runner) execute(ctx context.Context, task *Task, worker int) *TaskResult {
+	// 回放时，直接使用记录的执行结果
+	if r.replay != nil {
+		return r.replay.result(task)
+	}
+	// 命中缓存时，无需执行任务
+	if result := r.cache.lookup(task); result != nil {
+		return result
+	}
+
+	var (
+		result  interface{}
+		attempt uint32 = 1
+		retried uint32
+		err     error
+		// 仅在需感知任务当前执行次数时，才在运行上下文中记录，以免不必要的开销
+		attempted = r.faults != nil || r.watchdog != nil || r.logger != nil || task.retries > 0
+	)
+	if r.watchdog != nil {
+		ctx = withWorker(ctx, worker)
+	}
+	for {
+		attemptCtx := ctx
+		if attempted {
+			attemptCtx = r.logger.taskContext(withAttempt(ctx, retried+1), task, worker)
+		}
+		// 重试时，再次记录任务开始执行
+		if retried > 0 {
+			r.logger.started(attemptCtx, task, worker)
+		}
+		if r.stragglers != nil {
+			result, attempt, err = r.stragglers.execute(attemptCtx, task, r.call)
+		} else {
+			result, err = r.call(attemptCtx, task)
+		}
+		// 执行失败时，任务组未被取消则重试
+		if err == nil || retried >= task.retries || context.Cause(ctx) != nil {
+			break
+		}
+		retried++
+	}
+	taskResult := newTaskResult(task, result, err)
+	taskResult.attempt = attempt + retried
+	taskResult.faults = r.faults.injectedFaults(task.fNO)
+	r.cache.store(task, taskResult)
+	return taskResult
+}
+
+// finish 结束本次运行，并返回运行统计
+func (r *runner) finish(tasks []*Task) *RunStats {
+	stats := r.stats.finish()
+	stats.Stragglers = r.stragglers.list()
+	stats.Children = childStats(tasks)
+	r.cleaner.report(stats)
+	r.throttle.report(stats)
+	r.metrics.observeCancelled(stats.Tasks - stats.Succeeded - stats.Failed)
+	return stats
 }
 
 // TaskResult 表示任务的执行结果与执行状态
@@ -229,6 +621,23 @@ type TaskResult struct {
 	fNO    uint32
 	result interface{}
 	err    error
+	shed   bool // 任务是否因准入控制被丢弃(未执行)
+
+	attempt uint32      // 任务的执行次数
+	cached  bool        // 任务结果是否来自缓存(未执行)
+	faults  []FaultKind // 任务被注入的故障
+
+	encoded   *encodedResult // 编码后的任务结果(来自检查点、磁盘缓存或回放)，以便按需解码为指定的类型
+	discarded atomic.Bool    // 任务结果是否已被清理，见[WithCleanup]
+	delivered atomic.Bool    // 任务结果是否已通过句柄交付给调用方，见[Future]
+
+	name        string
+	description string
+	labels      map[string]string
+}
+
+func newTaskResult(task *Task, result interface{}, err error) *TaskResult {
+	return &TaskResult{fNO: task.fNO, result: result, err: err, name: task.name, description: task.description, labels: task.labels}
 }
 
 // FNO 获取任务的唯一标识号
@@ -241,13 +650,13 @@ func (tr *TaskResult) FNO() uint32 {
 
 // Result 获取任务执行结果
 func (tr *TaskResult) Result() interface{} {
-	if tr == nil {
+	if tr == nil || tr.discarded.Load() {
 		return nil
 	}
 	return tr.result
 }
 
-// Error 获取任务执行状态
+// Error 获取任务执行状态，任务执行失败时，为[*TaskError]
 func (tr *TaskResult) Error() error {
 	if tr == nil {
 		return nil
@@ -255,6 +664,22 @@ func (tr *TaskResult) Error() error {
 	return tr.err
 }
 
+// Attempt 获取任务的执行次数(如，推测执行时可能为2)，任务未执行(如，被丢弃或命中缓存)时为0
+func (tr *TaskResult) Attempt() uint32 {
+	if tr == nil {
+		return 0
+	}
+	return tr.attempt
+}
+
+// Shed 获取任务是否因准入控制被丢弃(未执行)，被丢弃任务的执行状态为[ErrQueueFull]
+func (tr *TaskResult) Shed() bool {
+	if tr == nil {
+		return false
+	}
+	return tr.shed
+}
+
 // If 简单的三元表达式实现
 var If = func(cond bool, a, b interface{}) interface{} {
 	if cond {
@@ -1,6 +1,7 @@
 package taskgroup_test
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -42,9 +43,9 @@ func ExampleTaskGroup_typical() {
 		fmt.Printf("FNO: %d, RESULT: %v , STATUS: %v\n", fno, result.Result(), result.Error())
 	}
 	// Unordered output:
-	// FNO: 3, RESULT: TASK3: The data is 928 , STATUS: fno: 3, TASK3 err
+	// FNO: 3, RESULT: TASK3: The data is 928 , STATUS: task 3: fno: 3, TASK3 err
 	// FNO: 2, RESULT: {1112 mlee} , STATUS: <nil>
-	// FNO: 1, RESULT: 1127 , STATUS: fno: 1, TASK1 err
+	// FNO: 1, RESULT: 1127 , STATUS: task 1: fno: 1, TASK1 err
 }
 
 // Default 展示了默认配置的使用案例，包括，多任务创建、任务执行、结果收集，错误处理等
@@ -64,7 +65,7 @@ func ExampleTaskGroup_default() {
 		fmt.Printf("FNO: %d, RESULT: %v , STATUS: %v\n", fno, result.Result(), result.Error())
 	}
 	// Output:
-	// err: fno: 3, TASK3 err
+	// err: task 3: fno: 3, TASK3 err
 }
 
 // JustNotBad 展示了非最佳的使用案例，包括，多任务创建、任务执行、结果收集，错误处理等
@@ -86,8 +87,8 @@ func ExampleTaskGroup_justNotBad() {
 		fmt.Printf("FNO: %d, RESULT: %v , STATUS: %v\n", fno, result.Result(), result.Error())
 	}
 	// Unordered output:
-	// FNO: 3, RESULT: TASK3: The data is 928 , STATUS: fno: 2, TASK3 err
-	// FNO: 1, RESULT: 1127 , STATUS: fno: 1, TASK1 err
+	// FNO: 3, RESULT: TASK3: The data is 928 , STATUS: task 3: fno: 2, TASK3 err
+	// FNO: 1, RESULT: 1127 , STATUS: task 1: fno: 1, TASK1 err
 	// FNO: 2, RESULT: {1112 mlee} , STATUS: <nil>
 }
 
@@ -118,3 +119,29 @@ func ExampleTaskGroup_abnormal() {
 	// Output:
 	// err: AddTask: Already have the same Task 2
 }
+
+// Results 展示了以[taskgroup.Results]的形式获取执行结果，并按任务编号有序遍历
+func ExampleResults() {
+	tasks := []*taskgroup.Task{
+		taskgroup.NewTask(1, task1ReturnFailWrapper(1, false), false),
+		taskgroup.NewTask(2, task2ReturnSuccessWrapper(2, false), true),
+		taskgroup.NewTask(3, task3ReturnFailWrapper(3, false), false),
+	}
+
+	results, err := taskgroup.NewTaskGroup().AddTask(tasks...).RunResults(context.Background())
+	if err != nil {
+		fmt.Printf("err: %+v\n", err)
+		return
+	}
+	results.Each(func(result *taskgroup.TaskResult) bool {
+		fmt.Printf("FNO: %d, RESULT: %v , STATUS: %v\n", result.FNO(), result.Result(), result.Error())
+		return true
+	})
+	data, _ := taskgroup.ResultAs[task2Struct](results, 2)
+	fmt.Printf("TASK2: %+v\n", data)
+	// Output:
+	// FNO: 1, RESULT: 1127 , STATUS: task 1: fno: 1, TASK1 err
+	// FNO: 2, RESULT: {1112 mlee} , STATUS: <nil>
+	// FNO: 3, RESULT: TASK3: The data is 928 , STATUS: task 3: fno: 3, TASK3 err
+	// TASK2: {a:1112 b:mlee}
+}
@@ -0,0 +1,125 @@
+package taskgrouptest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+// FakeClock 可控的时钟(实现了[taskgroup.Clock])，仅在调用[FakeClock.Advance]时推进时间
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+	tickers map[*fakeTicker]struct{}
+}
+
+// waiter 等待时钟到达`deadline`的计时者
+type waiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFakeClock 创建一个当前时间为`now`的时钟
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now 获取当前时间
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After 在时钟被推进`d`时长后，向返回的`channel`中发送当时的时间，`d`不大于0时，立即发送
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, waiter{c.now.Add(d), ch})
+	return ch
+}
+
+// NewTicker 创建一个时钟每被推进`d`时长，向其`channel`中发送当时时间的计时器，与[time.Ticker]一致，
+// 接收不及时的时间将被丢弃
+func (c *FakeClock) NewTicker(d time.Duration) taskgroup.Ticker {
+	if d <= 0 {
+		panic("taskgrouptest: non-positive interval for NewTicker")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{clock: c, period: d, next: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if c.tickers == nil {
+		c.tickers = make(map[*fakeTicker]struct{})
+	}
+	c.tickers[t] = struct{}{}
+	return t
+}
+
+// Advance 推进时间`d`，并通知所有已到期的计时者及周期性计时器
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(c.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = waiters
+	for t := range c.tickers {
+		if t.next.After(c.now) {
+			continue
+		}
+		select {
+		case t.ch <- c.now:
+		default:
+		}
+		for !t.next.After(c.now) {
+			t.next = t.next.Add(t.period)
+		}
+	}
+}
+
+// Waiters 获取还未到期的计时者数量，不包括周期性计时器(如，任务组内部用于落后任务检测、看门狗的计时器)
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil 等待直至至少存在`n`个还未到期的计时者，以确保推进时间前，被测代码已开始计时，
+// 任务组内部的周期性计时器(见[FakeClock.Waiters])不会满足等待条件
+func (c *FakeClock) BlockUntil(n int) {
+	for c.Waiters() < n {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// fakeTicker 由[FakeClock]创建的周期性计时器
+type fakeTicker struct {
+	clock  *FakeClock
+	period time.Duration
+	next   time.Time // 下一次发送时间的时刻
+	ch     chan time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	delete(t.clock.tickers, t)
+}
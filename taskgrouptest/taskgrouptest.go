@@ -0,0 +1,129 @@
+// Package taskgrouptest 提供了测试使用[taskgroup.TaskGroup]的代码时所需的工具：
+// 确定性的执行方式、可控的时钟、预置行为的任务、执行结果的断言及协程泄露的检测
+package taskgrouptest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+// leakTimeout 检测协程泄露时，等待任务组内部协程退出的最长时长
+const leakTimeout = time.Second
+
+// Deterministic 使任务组在单个协程上按确定的顺序依次执行任务(见[taskgroup.WithSequential])，
+// 并使用时钟`clock`计时，`clock`为`nil`时，使用系统时钟
+func Deterministic(clock taskgroup.Clock) taskgroup.Option {
+	return func(tg *taskgroup.TaskGroup) {
+		taskgroup.WithSequential()(tg)
+		if clock != nil {
+			taskgroup.WithGroupClock(clock)(tg)
+		}
+	}
+}
+
+// Run 运行任务组`tg`，并检测运行结束后是否有协程泄露
+func Run(t testing.TB, tg *taskgroup.TaskGroup) (taskgroup.Results, error) {
+	t.Helper()
+	results, err := tg.RunResults(context.Background())
+	RequireNoLeaks(t)
+	return results, err
+}
+
+// RequireNoLeaks 断言不存在仍在执行任务组内部代码的协程(如，`worker`、任务分发等)，
+// 任务组结束后，其内部协程可能稍晚退出，故至多等待[leakTimeout]
+//
+// 被放弃执行的任务(如，推测执行中未被采用的执行、被看门狗放弃或超时的执行)，其`ctx`将被取消，可感知运行上下文的任务
+// 应及时退出；不可感知运行上下文的任务在执行结束前，同样会被视为泄露
+//
+// NOTEs: 同时运行的其他任务组(如，并行的测试)也会被视为泄露
+func RequireNoLeaks(t testing.TB) {
+	t.Helper()
+	deadline := time.Now().Add(leakTimeout)
+	for {
+		leaked := leakedGoroutines()
+		if len(leaked) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("taskgrouptest: %d goroutine(s) leaked:\n\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// leakedGoroutines 获取栈中含有任务组内部代码的协程
+func leakedGoroutines() []string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	const pkgPrefix = "github.com/mlee-msl/taskgroup."
+	var leaked []string
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		for _, line := range strings.Split(stack, "\n") {
+			if strings.HasPrefix(line, pkgPrefix) {
+				leaked = append(leaked, stack)
+				break
+			}
+		}
+	}
+	return leaked
+}
+
+// RequireSucceeded 断言任务`fNO`执行成功，并返回其执行结果
+func RequireSucceeded(t testing.TB, results taskgroup.Results, fNO uint32) *taskgroup.TaskResult {
+	t.Helper()
+	result, err := results.Get(fNO)
+	if err != nil {
+		t.Fatalf("taskgrouptest: %v", err)
+	}
+	if result.Error() != nil {
+		t.Fatalf("taskgrouptest: task %d failed: %v", fNO, result.Error())
+	}
+	return result
+}
+
+// RequireFailed 断言任务`fNO`执行失败，且其错误信息与`target`匹配(`errors.Is`)，`target`为`nil`时，不检查错误信息
+func RequireFailed(t testing.TB, results taskgroup.Results, fNO uint32, target error) *taskgroup.TaskResult {
+	t.Helper()
+	result, err := results.Get(fNO)
+	if err != nil {
+		t.Fatalf("taskgrouptest: %v", err)
+	}
+	if result.Error() == nil {
+		t.Fatalf("taskgrouptest: task %d succeeded, expected failure", fNO)
+	}
+	if target != nil && !errors.Is(result.Error(), target) {
+		t.Fatalf("taskgrouptest: task %d err=%v, expected %v", fNO, result.Error(), target)
+	}
+	return result
+}
+
+// RequireResult 断言任务`fNO`执行成功，且执行结果与`expected`相等(`reflect.DeepEqual`)
+func RequireResult(t testing.TB, results taskgroup.Results, fNO uint32, expected interface{}) {
+	t.Helper()
+	if result := RequireSucceeded(t, results, fNO); !reflect.DeepEqual(result.Result(), expected) {
+		t.Fatalf("taskgrouptest: task %d result=%+v, expected %+v", fNO, result.Result(), expected)
+	}
+}
+
+// RequireNotRun 断言任务`fNO`不在执行结果中(如，任务组失败后未被执行)
+func RequireNotRun(t testing.TB, results taskgroup.Results, fNO uint32) {
+	t.Helper()
+	if result, has := results[fNO]; has {
+		t.Fatalf("taskgrouptest: task %d ran, result=%+v, err=%v", fNO, result.Result(), result.Error())
+	}
+}
@@ -0,0 +1,206 @@
+package taskgrouptest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+	"github.com/mlee-msl/taskgroup/taskgrouptest"
+)
+
+func TestDeterministic(t *testing.T) {
+	errTask := errors.New("task err")
+	var previous []uint32
+	for run := 0; run < 10; run++ {
+		var (
+			mu    sync.Mutex
+			order []uint32
+		)
+		observe := func(fNO uint32) taskgroup.TaskFunc {
+			return func() (interface{}, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, fNO)
+				return fNO, nil
+			}
+		}
+		tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(4), taskgrouptest.Deterministic(nil)).AddTask(
+			taskgroup.NewTask(5, observe(5), false),
+			taskgroup.NewTask(2, observe(2), true),
+			taskgroup.NewTask(4, observe(4), false),
+			taskgroup.NewTask(1, observe(1), false),
+			taskgroup.NewTask(3, observe(3), true),
+			taskgrouptest.Failing(6, errTask, false),
+		)
+		results, err := taskgrouptest.Run(t, tg)
+		if err != nil {
+			t.Fatalf("err: %+v", err)
+		}
+		taskgrouptest.RequireResult(t, results, 4, uint32(4))
+		taskgrouptest.RequireFailed(t, results, 6, errTask)
+		// 必要成功的任务优先，其次按任务编号升序
+		if expected := []uint32{2, 3, 1, 4, 5}; !reflect.DeepEqual(order, expected) {
+			t.Fatalf("run=%d, order=%v, expected=%v", run, order, expected)
+		}
+		if previous != nil && !reflect.DeepEqual(order, previous) {
+			t.Fatalf("run=%d, order=%v, previous=%v", run, order, previous)
+		}
+		previous = order
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	clock := taskgrouptest.NewFakeClock(start)
+	tg := taskgroup.NewTaskGroup(taskgrouptest.Deterministic(clock)).AddTask(
+		taskgrouptest.Slow(1, clock, time.Hour, "slow", true),
+		taskgrouptest.Succeeding(2, "fast", false),
+	)
+
+	type outcome struct {
+		results taskgroup.Results
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		results, err := tg.RunResults(context.Background())
+		done <- outcome{results, err}
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	out := <-done
+	if out.err != nil {
+		t.Fatalf("err: %+v", out.err)
+	}
+	taskgrouptest.RequireResult(t, out.results, 1, "slow")
+	taskgrouptest.RequireResult(t, out.results, 2, "fast")
+	// 运行耗时以可控的时钟计量
+	if stats := tg.Stats(); !stats.StartedAt.Equal(start) || stats.Elapsed != time.Hour {
+		t.Errorf("stats=%+v", stats)
+	}
+	taskgrouptest.RequireNoLeaks(t)
+}
+
+func TestFakeClock_ticker(t *testing.T) {
+	clock := taskgrouptest.NewFakeClock(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	ticker := clock.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	// 周期性计时器不计入计时者
+	if waiters := clock.Waiters(); waiters != 0 {
+		t.Fatalf("waiters=%d", waiters)
+	}
+	clock.Advance(30 * time.Second)
+	select {
+	case now := <-ticker.C():
+		t.Fatalf("unexpected tick at %v", now)
+	default:
+	}
+	// 接收不及时的时间被丢弃
+	clock.Advance(3 * time.Minute)
+	if now := <-ticker.C(); !now.Equal(clock.Now()) {
+		t.Errorf("tick=%v, expected=%v", now, clock.Now())
+	}
+	select {
+	case now := <-ticker.C():
+		t.Fatalf("unexpected tick at %v", now)
+	default:
+	}
+	clock.Advance(time.Minute)
+	if now := <-ticker.C(); !now.Equal(clock.Now()) {
+		t.Errorf("tick=%v, expected=%v", now, clock.Now())
+	}
+	ticker.Stop()
+	clock.Advance(time.Hour)
+	select {
+	case now := <-ticker.C():
+		t.Fatalf("tick after stop at %v", now)
+	default:
+	}
+}
+
+func TestFakeClock_internalTickers(t *testing.T) {
+	clock := taskgrouptest.NewFakeClock(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	tg := taskgroup.NewTaskGroup(
+		taskgrouptest.Deterministic(clock),
+		taskgroup.WithStragglerDetection(3, false),
+		taskgroup.WithWatchdog(2*time.Hour, func(taskgroup.HungTask) {}, false),
+	).AddTask(taskgrouptest.Slow(1, clock, time.Hour, "slow", true))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := tg.Run()
+		done <- err
+	}()
+	// 落后任务检测与看门狗的周期性计时器不计入计时者，`BlockUntil`仅在任务开始计时后返回
+	clock.BlockUntil(1)
+	if waiters := clock.Waiters(); waiters != 1 {
+		t.Fatalf("waiters=%d", waiters)
+	}
+	clock.Advance(time.Hour)
+	if err := <-done; err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+	taskgrouptest.RequireNoLeaks(t)
+}
+
+func TestRequireNoLeaks_speculation(t *testing.T) {
+	var calls atomic.Int32
+	tasks := []*taskgroup.Task{
+		taskgroup.NewContextTask(1, func(ctx context.Context) (interface{}, error) {
+			if calls.Add(1) == 1 { // 首次执行缓慢，直至被取消
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return "slow", nil
+		}, true),
+	}
+	for i := 2; i <= 10; i++ {
+		tasks = append(tasks, taskgrouptest.Slow(uint32(i), nil, 2*time.Millisecond, "fast", false))
+	}
+
+	// 推测执行中未被采用的执行被取消后退出，不会泄露
+	results, err := taskgrouptest.Run(t, taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(3), taskgroup.WithStragglerDetection(3, true)).AddTask(tasks...))
+	if err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+	taskgrouptest.RequireResult(t, results, 1, "slow")
+}
+
+func TestRequireNoLeaks(t *testing.T) {
+	errCancel := errors.New("cancelled")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errCancel)
+
+	// 任务组被取消后，内部协程均应退出
+	tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(3)).AddTask(
+		taskgrouptest.Slow(1, nil, time.Minute, nil, true),
+		taskgrouptest.Slow(2, nil, time.Minute, nil, false),
+		taskgrouptest.Slow(3, nil, time.Minute, nil, false),
+	)
+	if _, err := tg.RunContext(ctx); !errors.Is(err, errCancel) {
+		t.Fatalf("err: %+v", err)
+	}
+	taskgrouptest.RequireNoLeaks(t)
+}
+
+func ExampleDeterministic() {
+	task := func(fNO uint32) *taskgroup.Task {
+		return taskgroup.NewTask(fNO, func() (interface{}, error) {
+			fmt.Printf("task %d\n", fNO)
+			return nil, nil
+		}, fNO == 3)
+	}
+	_, _ = taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(3), taskgrouptest.Deterministic(nil)).AddTask(task(2), task(1), task(3)).Run()
+
+	// Output:
+	// task 3
+	// task 1
+	// task 2
+}
@@ -0,0 +1,34 @@
+package taskgrouptest
+
+import (
+	"context"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+// Succeeding 创建一个执行成功并返回`result`的任务
+func Succeeding(fNO uint32, result interface{}, mustSuccess bool, opts ...taskgroup.TaskOption) *taskgroup.Task {
+	return taskgroup.NewTask(fNO, func() (interface{}, error) { return result, nil }, mustSuccess, opts...)
+}
+
+// Failing 创建一个执行失败并返回`err`的任务
+func Failing(fNO uint32, err error, mustSuccess bool, opts ...taskgroup.TaskOption) *taskgroup.Task {
+	return taskgroup.NewTask(fNO, func() (interface{}, error) { return nil, err }, mustSuccess, opts...)
+}
+
+// Slow 创建一个在时钟`clock`经过`d`时长后执行成功并返回`result`的任务，任务组先被取消时，返回取消的原因，
+// `clock`为`nil`时，默认为[taskgroup.SystemClock]
+func Slow(fNO uint32, clock taskgroup.Clock, d time.Duration, result interface{}, mustSuccess bool, opts ...taskgroup.TaskOption) *taskgroup.Task {
+	if clock == nil {
+		clock = taskgroup.SystemClock
+	}
+	return taskgroup.NewContextTask(fNO, func(ctx context.Context) (interface{}, error) {
+		select {
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		case <-clock.After(d):
+			return result, nil
+		}
+	}, mustSuccess, opts...)
+}
@@ -0,0 +1,96 @@
+package taskgroup
+
+import (
+	"context"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// memoryThrottleInterval 内存使用量超过软上限时，再次探测的间隔
+const memoryThrottleInterval = 10 * time.Millisecond
+
+// MemoryProbe 表示获取当前内存使用量(字节)的探针
+type MemoryProbe func() uint64
+
+// HeapProbe 基于`runtime/metrics`的探针，获取堆上对象占用的内存(含还未被回收的对象)
+func HeapProbe() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// WithMemoryThrottle 开启内存压力感知的限流，内存使用量超过软上限`softLimit`(字节)时，暂停分发新的任务，
+// 直至回落到软上限以下，`probe`为`nil`时，默认为[HeapProbe]，`bypassMustSuccess`为`true`时，必要成功的任务不受限流影响
+//
+// 被暂停分发的任务数及其等待时长记录在运行统计[RunStats.Throttled]、[RunStats.ThrottledWait]中
+func WithMemoryThrottle(softLimit uint64, probe MemoryProbe, bypassMustSuccess bool) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.memorySoftLimit = softLimit
+		tg.memoryProbe = If(probe != nil, probe, MemoryProbe(HeapProbe)).(MemoryProbe)
+		tg.throttleBypass = bypassMustSuccess
+	}
+}
+
+// memoryThrottle 任务组单次运行期间的内存限流
+type memoryThrottle struct {
+	softLimit uint64
+	probe     MemoryProbe
+	bypass    bool
+	clock     Clock
+
+	mu        sync.Mutex
+	throttled int
+	wait      time.Duration
+}
+
+func (tg *TaskGroup) newMemoryThrottle() *memoryThrottle {
+	if tg.memorySoftLimit == 0 {
+		return nil
+	}
+	return &memoryThrottle{softLimit: tg.memorySoftLimit, probe: tg.memoryProbe, bypass: tg.throttleBypass, clock: tg.runClock()}
+}
+
+// admit 内存使用量超过软上限时，等待直至其回落，`ctx`先被取消时，返回`false`
+func (mt *memoryThrottle) admit(ctx context.Context, task *Task) bool {
+	if mt == nil || (mt.bypass && task.mustSuccess) || mt.probe() <= mt.softLimit {
+		return true
+	}
+
+	startedAt := mt.clock.Now()
+	ticker := mt.clock.NewTicker(memoryThrottleInterval)
+	defer ticker.Stop()
+	defer func() {
+		mt.mu.Lock()
+		defer mt.mu.Unlock()
+		mt.throttled++
+		mt.wait += mt.clock.Now().Sub(startedAt)
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C():
+		}
+		if mt.probe() <= mt.softLimit {
+			return true
+		}
+	}
+}
+
+// report 将限流情况记录在运行统计`stats`中
+func (mt *memoryThrottle) report(stats *RunStats) {
+	if mt == nil {
+		return
+	}
+
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	stats.Throttled, stats.ThrottledWait = mt.throttled, mt.wait
+}
@@ -0,0 +1,48 @@
+package taskgroup_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_memoryThrottle(t *testing.T) {
+	const softLimit = 1 << 30
+	var (
+		usage   atomic.Uint64
+		started atomic.Int32
+	)
+	usage.Store(2 * softLimit)
+	task := func(fNO uint32, mustSuccess bool) *taskgroup.Task {
+		return taskgroup.NewTask(fNO, func() (interface{}, error) {
+			started.Add(1)
+			return nil, nil
+		}, mustSuccess)
+	}
+	tg := taskgroup.NewTaskGroup(
+		taskgroup.WithWorkerNums(2),
+		taskgroup.WithMemoryThrottle(softLimit, usage.Load, true),
+	).AddTask(task(1, true), task(2, false), task(3, false))
+
+	h := tg.Start()
+	// 必要成功的任务不受限流影响，其余任务待内存回落后执行
+	time.Sleep(50 * time.Millisecond)
+	if n := started.Load(); n != 1 {
+		t.Fatalf("started=%d before memory drops", n)
+	}
+	usage.Store(softLimit / 2)
+	if _, err := h.Wait(); err != nil || started.Load() != 3 {
+		t.Fatalf("started=%d, err=%+v", started.Load(), err)
+	}
+	if stats := tg.Stats(); stats.Throttled != 2 || stats.ThrottledWait < 40*time.Millisecond {
+		t.Errorf("throttled=%d, wait=%v", stats.Throttled, stats.ThrottledWait)
+	}
+}
+
+func TestHeapProbe(t *testing.T) {
+	if taskgroup.HeapProbe() == 0 {
+		t.Error("heap probe returned 0")
+	}
+}
@@ -0,0 +1,51 @@
+package taskgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTaskTimeout 任务单次执行超时，见[WithTimeout]
+var ErrTaskTimeout = errors.New("taskgroup: task timed out")
+
+// WithTimeout 指定任务单次执行的超时时长`timeout`，超时后该次执行将以[ErrTaskTimeout]失败，
+// 可感知运行上下文的任务其`ctx`也将被取消，而不可感知运行上下文的任务方法仍会在后台运行至结束
+func WithTimeout(timeout time.Duration) TaskOption {
+	return func(t *Task) {
+		if t == nil {
+			return
+		}
+		t.timeout = timeout
+	}
+}
+
+// withTimeout 按任务的超时时长包装任务的执行方法`call`，任务未指定超时时长时，直接执行
+func (r *runner) withTimeout(call func(ctx context.Context, task *Task) (interface{}, error)) func(ctx context.Context, task *Task) (interface{}, error) {
+	return func(ctx context.Context, task *Task) (interface{}, error) {
+		if task.timeout <= 0 {
+			return call(ctx, task)
+		}
+
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+		done := make(chan outcome, 1)
+		go func() {
+			result, err := call(ctx, task)
+			done <- outcome{result: result, err: err}
+		}()
+		select {
+		case o := <-done:
+			return o.result, o.err
+		case <-r.clock.After(task.timeout):
+			err := fmt.Errorf("%w after %v", ErrTaskTimeout, task.timeout)
+			cancel(err)
+			r.cleaner.discardLater(task, done)
+			return nil, err
+		case <-ctx.Done():
+			r.cleaner.discardLater(task, done)
+			return nil, context.Cause(ctx)
+		}
+	}
+}
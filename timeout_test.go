@@ -0,0 +1,38 @@
+package taskgroup_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func TestTaskGroupRun_timeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	results, err := taskgroup.NewTaskGroup().AddTask(
+		taskgroup.NewTask(1, func() (interface{}, error) {
+			<-release
+			return "late", nil
+		}, false, taskgroup.WithTimeout(10*time.Millisecond)),
+		taskgroup.NewContextTask(2, func(ctx context.Context) (interface{}, error) {
+			<-ctx.Done()
+			return nil, context.Cause(ctx)
+		}, false, taskgroup.WithTimeout(10*time.Millisecond)),
+		taskgroup.NewTask(3, func() (interface{}, error) { return "ok", nil }, true, taskgroup.WithTimeout(time.Minute)),
+	).Run()
+	if err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+	if err := results[1].Error(); !errors.Is(err, taskgroup.ErrTaskTimeout) {
+		t.Errorf("err=%+v", err)
+	}
+	if err := results[2].Error(); !errors.Is(err, taskgroup.ErrTaskTimeout) {
+		t.Errorf("err=%+v", err)
+	}
+	if results[3].Result() != "ok" {
+		t.Errorf("result=%+v", results[3].Result())
+	}
+}
@@ -0,0 +1,225 @@
+package taskgroup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrTaskHung 任务的执行时长超过了看门狗的阈值，且被放弃执行
+var ErrTaskHung = errors.New("taskgroup: task hung")
+
+// HungTask 表示执行时长超过看门狗阈值的任务
+type HungTask struct {
+	FNO     uint32        // 任务编号(标识)
+	Name    string        // 任务名称
+	Worker  int           // 执行任务的`worker`编号
+	Attempt uint32        // 任务当前是第几次执行(含重试)
+	Elapsed time.Duration // 任务已执行的时长
+	Stack   []byte        // 执行任务的协程栈
+}
+
+// WithWatchdog 开启看门狗，当任务的执行时长超过阈值`threshold`时，将其信息(含执行任务的协程栈)报告给`report`，每个任务仅报告一次，
+// `report`为`nil`时，默认通过标准库`log`输出
+//
+// `abandon`为`true`时，将放弃继续等待超时的任务，并以[ErrTaskHung]结束任务组，此时，任务将在独立的协程中执行，
+// 被放弃的任务其`ctx`将被取消(原因为[ErrTaskHung])，不可感知运行上下文的任务将在后台继续执行，任务组不会等待其结束
+func WithWatchdog(threshold time.Duration, report func(hung HungTask), abandon bool) Option {
+	return func(tg *TaskGroup) {
+		if tg == nil {
+			return
+		}
+		tg.watchdogThreshold = threshold
+		tg.watchdogReport = report
+		tg.watchdogAbandon = abandon
+	}
+}
+
+// logHungTask 默认的看门狗报告方式
+func logHungTask(hung HungTask) {
+	log.Printf("taskgroup: task %d (%s) on worker %d (attempt %d) has been running for %v\n%s", hung.FNO, hung.Name, hung.Worker, hung.Attempt, hung.Elapsed, hung.Stack)
+}
+
+// watchdog 任务组单次运行期间的看门狗
+type watchdog struct {
+	threshold time.Duration
+	report    func(hung HungTask)
+	abandon   bool
+	clock     Clock
+	fail      func(err error) // 结束任务组
+	cleaner   *resultCleaner  // 清理被放弃的执行的结果
+
+	mu      sync.Mutex
+	running map[*watch]struct{}
+}
+
+// watch 看门狗对任务的一次执行的监视
+type watch struct {
+	task      *Task
+	worker    int
+	attempt   uint32 // 任务当前的执行次数
+	startedAt time.Time
+	goid      string // 执行任务的协程编号
+	reported  bool
+	abandoned chan struct{}
+	cancel    context.CancelCauseFunc // 取消被放弃的任务的`ctx`
+}
+
+// workerKey 运行上下文中执行任务的协程编号的键
+type workerKey struct{}
+
+// withWorker 在运行上下文`ctx`中记录执行任务的协程编号`worker`
+func withWorker(ctx context.Context, worker int) context.Context {
+	return context.WithValue(ctx, workerKey{}, worker)
+}
+
+// workerOf 获取运行上下文`ctx`中记录的执行任务的协程编号，未记录时，为0
+func workerOf(ctx context.Context) int {
+	worker, _ := ctx.Value(workerKey{}).(int)
+	return worker
+}
+
+// newWatchdog 按照任务组的配置创建看门狗，并在`ctx`结束前周期性的检查，放弃任务时，通过`fail`结束任务组
+func (tg *TaskGroup) newWatchdog(ctx context.Context, fail func(err error), cleaner *resultCleaner) *watchdog {
+	if tg.watchdogThreshold <= 0 {
+		return nil
+	}
+
+	w := &watchdog{
+		threshold: tg.watchdogThreshold,
+		report:    tg.watchdogReport,
+		abandon:   tg.watchdogAbandon,
+		clock:     tg.runClock(),
+		fail:      fail,
+		cleaner:   cleaner,
+		running:   make(map[*watch]struct{}),
+	}
+	if w.report == nil {
+		w.report = logHungTask
+	}
+	interval := If(w.threshold/4 > time.Millisecond, w.threshold/4, time.Millisecond).(time.Duration)
+	ticker := w.clock.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C():
+				w.check(now)
+			}
+		}
+	}()
+	return w
+}
+
+// wrap 通过`call`执行任务时，由看门狗进行监视，未开启看门狗时，直接返回`call`
+func (w *watchdog) wrap(call func(ctx context.Context, task *Task) (interface{}, error)) func(ctx context.Context, task *Task) (interface{}, error) {
+	if w == nil {
+		return call
+	}
+	return func(ctx context.Context, task *Task) (interface{}, error) {
+		wt := &watch{task: task, worker: workerOf(ctx), attempt: attemptOf(ctx), startedAt: w.clock.Now(), abandoned: make(chan struct{})}
+		defer func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			delete(w.running, wt)
+		}()
+		if !w.abandon {
+			w.start(wt, goroutineID())
+			return call(ctx, task)
+		}
+
+		callCtx, cancel := context.WithCancelCause(ctx)
+		wt.cancel = cancel
+		done := make(chan outcome, 1)
+		go func() {
+			w.start(wt, goroutineID())
+			result, err := call(callCtx, task)
+			done <- outcome{result, err}
+		}()
+		select {
+		case out := <-done:
+			return out.result, out.err
+		case <-wt.abandoned:
+			w.cleaner.discardLater(task, done)
+			return nil, ErrTaskHung
+		case <-ctx.Done(): // 任务组已结束，无需再等待
+			w.cleaner.discardLater(task, done)
+			return nil, context.Cause(ctx)
+		}
+	}
+}
+
+func (w *watchdog) start(wt *watch, goid string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	wt.goid = goid
+	w.running[wt] = struct{}{}
+}
+
+// check 检查执行时长超过阈值的任务
+func (w *watchdog) check(now time.Time) {
+	var hung []*watch
+	w.mu.Lock()
+	for wt := range w.running {
+		if !wt.reported && now.Sub(wt.startedAt) > w.threshold {
+			wt.reported = true
+			hung = append(hung, wt)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, wt := range hung {
+		elapsed := now.Sub(wt.startedAt)
+		w.report(HungTask{FNO: wt.task.fNO, Name: wt.task.name, Worker: wt.worker, Attempt: wt.attempt, Elapsed: elapsed, Stack: goroutineStack(wt.goid)})
+		if w.abandon {
+			close(wt.abandoned)
+			wt.cancel(ErrTaskHung)
+			w.fail(&TaskError{FNO: wt.task.fNO, Name: wt.task.name, MustSuccess: wt.task.mustSuccess, Attempt: wt.attempt, StartedAt: wt.startedAt, Duration: elapsed, Err: ErrTaskHung})
+		}
+	}
+}
+
+// goroutineID 获取当前协程的编号
+func goroutineID() string {
+	var buf [64]byte
+	stack := buf[:runtime.Stack(buf[:], false)]
+	// 格式如，`goroutine 18 [running]:`
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+	if i := bytes.IndexByte(stack, ' '); i > 0 {
+		if _, err := strconv.ParseUint(string(stack[:i]), 10, 64); err == nil {
+			return string(stack[:i])
+		}
+	}
+	return ""
+}
+
+// goroutineStack 获取编号为`goid`的协程栈，协程已结束时，返回`nil`
+func goroutineStack(goid string) []byte {
+	if goid == "" {
+		return nil
+	}
+
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	prefix := []byte("goroutine " + goid + " [")
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(stack, prefix) {
+			return stack
+		}
+	}
+	return nil
+}
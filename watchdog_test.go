@@ -0,0 +1,107 @@
+package taskgroup_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+// stuckTask 模拟死锁的任务
+func stuckTask(release <-chan struct{}) taskgroup.TaskFunc {
+	return func() (interface{}, error) {
+		<-release
+		return nil, nil
+	}
+}
+
+func TestTaskGroupRun_watchdog(t *testing.T) {
+	// 任务同时指定了超时时长时，报告的协程栈仍为执行任务的协程
+	testCases := []struct {
+		abandon bool
+		timeout time.Duration
+	}{
+		{false, 0},
+		{true, 0},
+		{false, time.Minute},
+		{true, time.Minute},
+	}
+	for _, testCase := range testCases {
+		var (
+			mu      sync.Mutex
+			hung    []taskgroup.HungTask
+			release = make(chan struct{})
+		)
+		tg := taskgroup.NewTaskGroup(taskgroup.WithWorkerNums(2), taskgroup.WithWatchdog(20*time.Millisecond, func(h taskgroup.HungTask) {
+			mu.Lock()
+			defer mu.Unlock()
+			hung = append(hung, h)
+			if !testCase.abandon {
+				close(release)
+			}
+		}, testCase.abandon)).AddTask(
+			taskgroup.NewTask(1, task2ReturnSuccessWrapper(1, false), false),
+			taskgroup.NewTask(2, stuckTask(release), false, taskgroup.WithName("stuck"), taskgroup.WithTimeout(testCase.timeout)),
+		)
+
+		_, err := tg.Run()
+		if testCase.abandon {
+			// 放弃超时的任务，即使其非必要成功，任务组也将失败
+			var taskErr *taskgroup.TaskError
+			if !errors.Is(err, taskgroup.ErrTaskHung) || !errors.As(err, &taskErr) || taskErr.FNO != 2 {
+				t.Errorf("abandon err=%+v", err)
+			}
+			close(release)
+		} else if err != nil {
+			t.Errorf("err=%+v", err)
+		}
+
+		mu.Lock()
+		if len(hung) != 1 || hung[0].FNO != 2 || hung[0].Name != "stuck" || hung[0].Elapsed < 20*time.Millisecond ||
+			!bytes.Contains(hung[0].Stack, []byte("stuckTask")) {
+			t.Errorf("abandon=%v, timeout=%v, hung=%+v", testCase.abandon, testCase.timeout, hung)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestTaskGroupRun_watchdogAbandonRetry(t *testing.T) {
+	var (
+		calls     atomic.Int32
+		hung      = make(chan taskgroup.HungTask, 1)
+		cancelled = make(chan error, 1)
+	)
+	tg := taskgroup.NewTaskGroup(taskgroup.WithWatchdog(20*time.Millisecond, func(h taskgroup.HungTask) { hung <- h }, true)).AddTask(
+		taskgroup.NewContextTask(1, func(ctx context.Context) (interface{}, error) {
+			if calls.Add(1) == 1 { // 首次执行失败，重试时卡住
+				return nil, errors.New("conn reset")
+			}
+			<-ctx.Done()
+			cancelled <- context.Cause(ctx)
+			return nil, ctx.Err()
+		}, false, taskgroup.WithRetries(1)),
+	)
+
+	_, err := tg.Run()
+	var taskErr *taskgroup.TaskError
+	if !errors.Is(err, taskgroup.ErrTaskHung) || !errors.As(err, &taskErr) || taskErr.Attempt != 2 {
+		t.Fatalf("err=%+v", err)
+	}
+	// 报告任务当前的执行次数，且被放弃的任务其`ctx`被取消
+	if h := <-hung; h.FNO != 1 || h.Attempt != 2 {
+		t.Errorf("hung=%+v", h)
+	}
+	select {
+	case cause := <-cancelled:
+		if !errors.Is(cause, taskgroup.ErrTaskHung) {
+			t.Errorf("cause=%+v", cause)
+		}
+	case <-time.After(time.Second):
+		t.Error("abandoned task not cancelled")
+	}
+}
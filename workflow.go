@@ -0,0 +1,194 @@
+package taskgroup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// registry 已注册的任务方法
+var registry = struct {
+	sync.RWMutex
+	funcs map[string]TaskFunc
+}{funcs: make(map[string]TaskFunc)}
+
+// Register 以名称`name`注册任务方法`f`，以供工作流(见[LoadWorkflow])按名称引用
+//
+// NOTEs: 名称为空、任务方法为`nil`或重复注册同一名称时，将会`panic`
+func Register(name string, f TaskFunc) {
+	if name == "" || f == nil {
+		panic("Register: task name is empty or task function is nil")
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	if _, has := registry.funcs[name]; has {
+		panic(fmt.Sprintf("Register: task %q already registered", name))
+	}
+	registry.funcs[name] = f
+}
+
+func registered(name string) (TaskFunc, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	f, has := registry.funcs[name]
+	return f, has
+}
+
+// Workflow 表示以`json`声明的任务组，如：
+//
+//	{"workers": 4, "tasks": [
+//		{"name": "fetch", "fno": 1, "mustSuccess": true, "timeout": "2s", "retries": 2},
+//		{"name": "report", "fno": 2, "dependsOn": [1]}
+//	]}
+type Workflow struct {
+	Workers uint32         `json:"workers,omitempty"` // 协程数，见[WithWorkerNums]
+	Tasks   []WorkflowTask `json:"tasks"`
+}
+
+// WorkflowTask 表示工作流中的一个任务
+type WorkflowTask struct {
+	Name        string   `json:"name"`                  // 任务方法的注册名称，见[Register]
+	FNO         uint32   `json:"fno"`                   // 任务编号
+	MustSuccess bool     `json:"mustSuccess,omitempty"` // 任务是否必须执行成功
+	Timeout     string   `json:"timeout,omitempty"`     // 单次执行的超时时长(如，"1.5s")，见[WithTimeout]
+	Retries     uint32   `json:"retries,omitempty"`     // 最大重试次数，见[WithRetries]
+	DependsOn   []uint32 `json:"dependsOn,omitempty"`   // 所依赖的任务编号，见[WithDependencies]
+}
+
+// WorkflowError 表示工作流中某个任务的校验错误
+type WorkflowError struct {
+	Index int    // 任务在工作流中的下标
+	FNO   uint32 // 任务编号
+	Field string // 出错的字段
+	Err   error
+}
+
+func (e *WorkflowError) Error() string {
+	return fmt.Sprintf("workflow: tasks[%d] (fno %d): %s: %v", e.Index, e.FNO, e.Field, e.Err)
+}
+
+func (e *WorkflowError) Unwrap() error {
+	return e.Err
+}
+
+// LoadWorkflow 从`r`中读取工作流，校验后创建对应的任务组，`opts`为任务组的其他选项
+//
+// NOTEs: 工作流中出现未知的字段、未注册的任务方法、重复的任务编号、非法的超时时长、未知的依赖或依赖成环时，均将返回错误
+func LoadWorkflow(r io.Reader, opts ...Option) (*TaskGroup, error) {
+	var w Workflow
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&w); err != nil {
+		return nil, fmt.Errorf("workflow: %w", err)
+	}
+	if dec.More() {
+		return nil, errors.New("workflow: unexpected data after the workflow")
+	}
+	return w.Build(opts...)
+}
+
+// Validate 校验工作流，错误信息将指出出错的任务，见[WorkflowError]
+func (w *Workflow) Validate() error {
+	_, err := w.tasks()
+	return err
+}
+
+// Build 校验工作流，并创建对应的任务组，`opts`为任务组的其他选项
+func (w *Workflow) Build(opts ...Option) (*TaskGroup, error) {
+	tasks, err := w.tasks()
+	if err != nil {
+		return nil, err
+	}
+	return NewTaskGroup(append([]Option{WithWorkerNums(w.Workers)}, opts...)...).AddTask(tasks...), nil
+}
+
+// tasks 校验工作流，并创建其中的所有任务
+func (w *Workflow) tasks() ([]*Task, error) {
+	if len(w.Tasks) == 0 {
+		return nil, errors.New("workflow: no tasks")
+	}
+
+	indexes := make(map[uint32]int, len(w.Tasks))
+	for i, wt := range w.Tasks {
+		if prev, has := indexes[wt.FNO]; has {
+			return nil, &WorkflowError{Index: i, FNO: wt.FNO, Field: "fno", Err: fmt.Errorf("duplicate of tasks[%d]", prev)}
+		}
+		indexes[wt.FNO] = i
+	}
+	tasks := make([]*Task, 0, len(w.Tasks))
+	for i, wt := range w.Tasks {
+		task, err := wt.task()
+		if err != nil {
+			err.Index = i
+			return nil, err
+		}
+		for _, dep := range wt.DependsOn {
+			if _, has := indexes[dep]; !has || dep == wt.FNO {
+				return nil, &WorkflowError{Index: i, FNO: wt.FNO, Field: "dependsOn", Err: fmt.Errorf("%s task %d", If(has, "self-dependent", "unknown").(string), dep)}
+			}
+		}
+		tasks = append(tasks, task)
+	}
+	if cycle := dependencyCycle(tasks); cycle != nil {
+		return nil, &WorkflowError{Index: indexes[cycle[0]], FNO: cycle[0], Field: "dependsOn", Err: fmt.Errorf("dependency cycle %v", cycle)}
+	}
+	return tasks, nil
+}
+
+// task 创建工作流任务对应的任务，错误信息中的任务下标需由调用方设置
+func (wt WorkflowTask) task() (*Task, *WorkflowError) {
+	f, has := registered(wt.Name)
+	if !has {
+		return nil, &WorkflowError{FNO: wt.FNO, Field: "name", Err: fmt.Errorf("task %q not registered", wt.Name)}
+	}
+	opts := []TaskOption{WithName(wt.Name), WithRetries(wt.Retries)}
+	if wt.Timeout != "" {
+		timeout, err := time.ParseDuration(wt.Timeout)
+		if err == nil && timeout <= 0 {
+			err = fmt.Errorf("non-positive timeout %q", wt.Timeout)
+		}
+		if err != nil {
+			return nil, &WorkflowError{FNO: wt.FNO, Field: "timeout", Err: err}
+		}
+		opts = append(opts, WithTimeout(timeout))
+	}
+	if len(wt.DependsOn) > 0 {
+		opts = append(opts, WithDependencies(wt.DependsOn...))
+	}
+	task := NewTask(wt.FNO, f, wt.MustSuccess, opts...)
+	task.registered = wt.Name
+	return task, nil
+}
+
+// Workflow 导出任务组对应的工作流，任务按编号升序排列，所有任务均需来自工作流(见[LoadWorkflow])，
+// 其中，协程数为指定的协程数(见[WithWorkerNums])，而非执行时调整后的协程数
+func (tg *TaskGroup) Workflow() (*Workflow, error) {
+	if tg == nil {
+		return nil, nil
+	}
+
+	w := &Workflow{Workers: tg.configuredWorkerNums, Tasks: make([]WorkflowTask, 0, len(tg.tasks))}
+	for _, task := range tg.tasks {
+		if task.registered == "" {
+			return nil, fmt.Errorf("Workflow: task %s is not from a workflow", task.ident())
+		}
+		wt := WorkflowTask{
+			Name:        task.registered,
+			FNO:         task.fNO,
+			MustSuccess: task.mustSuccess,
+			Retries:     task.retries,
+			DependsOn:   append([]uint32(nil), task.dependencies...),
+		}
+		if task.timeout > 0 {
+			wt.Timeout = task.timeout.String()
+		}
+		w.Tasks = append(w.Tasks, wt)
+	}
+	sort.Slice(w.Tasks, func(i, j int) bool { return w.Tasks[i].FNO < w.Tasks[j].FNO })
+	return w, nil
+}
@@ -0,0 +1,94 @@
+package taskgroup_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mlee-msl/taskgroup"
+)
+
+func init() {
+	taskgroup.Register("workflow.fetch", func() (interface{}, error) { return "fetched", nil })
+	taskgroup.Register("workflow.report", func() (interface{}, error) { return "reported", nil })
+}
+
+func TestLoadWorkflow(t *testing.T) {
+	const doc = `{"workers":2,"tasks":[` +
+		`{"name":"workflow.fetch","fno":1,"mustSuccess":true,"timeout":"1.5s","retries":2},` +
+		`{"name":"workflow.report","fno":2,"dependsOn":[1]}]}`
+	tg, err := taskgroup.LoadWorkflow(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+
+	// 导出的工作流与加载的一致
+	w, err := tg.Workflow()
+	if err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+	if exported, _ := json.Marshal(w); string(exported) != doc {
+		t.Errorf("exported=%s", exported)
+	}
+
+	results, err := tg.Run()
+	if err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+	if results[1].Result() != "fetched" || results[2].Result() != "reported" || results[2].Name() != "workflow.report" {
+		t.Errorf("results=%+v", results)
+	}
+
+	if _, err := taskgroup.NewTaskGroup().AddTask(taskgroup.NewTask(1, func() (interface{}, error) { return nil, nil }, true)).Workflow(); err == nil {
+		t.Error("want err for task not from a workflow")
+	}
+}
+
+func TestTaskGroupWorkflow_afterRun(t *testing.T) {
+	const doc = `{"workers":8,"tasks":[{"name":"workflow.fetch","fno":1},{"name":"workflow.report","fno":2,"dependsOn":[1]}]}`
+	tg, err := taskgroup.LoadWorkflow(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+	if _, err = tg.Run(); err != nil {
+		t.Fatalf("err=%+v", err)
+	}
+
+	// 执行时调整的协程数不影响导出的协程数
+	w, err := tg.Workflow()
+	if err != nil || w.Workers != 8 {
+		t.Errorf("workflow=%+v, err=%+v", w, err)
+	}
+}
+func TestLoadWorkflow_invalid(t *testing.T) {
+	for _, c := range []struct {
+		doc   string
+		index int
+		field string
+	}{
+		{doc: `{"tasks":[{"name":"workflow.fetch","fno":1},{"name":"workflow.missing","fno":2}]}`, index: 1, field: "name"},
+		{doc: `{"tasks":[{"name":"workflow.fetch","fno":1},{"name":"workflow.report","fno":1}]}`, index: 1, field: "fno"},
+		{doc: `{"tasks":[{"name":"workflow.fetch","fno":1,"timeout":"soon"}]}`, index: 0, field: "timeout"},
+		{doc: `{"tasks":[{"name":"workflow.fetch","fno":1,"timeout":"-1s"}]}`, index: 0, field: "timeout"},
+		{doc: `{"tasks":[{"name":"workflow.fetch","fno":1,"dependsOn":[3]}]}`, index: 0, field: "dependsOn"},
+		{doc: `{"tasks":[{"name":"workflow.fetch","fno":1,"dependsOn":[1]}]}`, index: 0, field: "dependsOn"},
+		{doc: `{"tasks":[{"name":"workflow.fetch","fno":1,"dependsOn":[2]},{"name":"workflow.report","fno":2,"dependsOn":[1]}]}`, index: 0, field: "dependsOn"},
+	} {
+		_, err := taskgroup.LoadWorkflow(strings.NewReader(c.doc))
+		var werr *taskgroup.WorkflowError
+		if !errors.As(err, &werr) || werr.Index != c.index || werr.Field != c.field {
+			t.Errorf("doc=%s, err=%+v", c.doc, err)
+		}
+	}
+
+	for _, doc := range []string{
+		`{"tasks":[]}`,
+		`{"tasks":[{"name":"workflow.fetch","fno":1,"mustSucceed":true}]}`,
+		`{"tasks":[{"name":"workflow.fetch","fno":1}]} {}`,
+	} {
+		if _, err := taskgroup.LoadWorkflow(strings.NewReader(doc)); err == nil {
+			t.Errorf("doc=%s, want err", doc)
+		}
+	}
+}